@@ -14,6 +14,11 @@ type AppEnv struct {
 	Log      zerolog.Logger
 	ConfDir  string
 	Services []AppService
+
+	// Clock is used by services to get the current time and to wait between
+	// retries. Replace it with a fake clock in tests before calling Configure.
+	// Services fall back to the SystemClock when nil, see ClockOrDefault.
+	Clock Clock
 }
 
 // NewAppEnv creates an AppEnv by loading configuration settings.
@@ -42,9 +47,20 @@ func NewAppEnv(confDir string, env string) *AppEnv {
 		Config:   cfg,
 		Log:      logger,
 		Services: make([]AppService, 0),
+		Clock:    SystemClock{},
 	}
 }
 
+// ClockOrDefault returns Clock, or the SystemClock when Clock is not set,
+// e.g. when the AppEnv was created without NewAppEnv.
+func (ctx *AppEnv) ClockOrDefault() Clock {
+	if ctx.Clock == nil {
+		return SystemClock{}
+	}
+
+	return ctx.Clock
+}
+
 func (ctx *AppEnv) AddService(service AppService) {
 	ctx.Services = append(ctx.Services, service)
 }
@@ -2,6 +2,7 @@ package goboot_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/nielskrijger/goboot"
 	"github.com/nielskrijger/goboot/mocks"
@@ -76,3 +77,10 @@ func TestAppContext_Close(t *testing.T) {
 	serviceMock1.AssertExpectations(t)
 	serviceMock2.AssertExpectations(t)
 }
+
+func TestAppContext_ClockOrDefault(t *testing.T) {
+	assert.Equal(t, goboot.SystemClock{}, (&goboot.AppEnv{}).ClockOrDefault())
+
+	clock := test.NewFakeClock(time.Now())
+	assert.Same(t, clock, (&goboot.AppEnv{Clock: clock}).ClockOrDefault())
+}
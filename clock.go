@@ -0,0 +1,32 @@
+package goboot
+
+import "time"
+
+// Clock provides the current time and lets services wait. Services use the
+// Clock of the AppEnv rather than the time package so tests can control time,
+// see test.FakeClock.
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	Sleep(d time.Duration)
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock implements Clock using the time package.
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (SystemClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+func (SystemClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+func (SystemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
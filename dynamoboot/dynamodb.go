@@ -19,6 +19,8 @@ var (
 
 	errMissingConfig = errors.New("missing dynamodb configuration")
 	errMissingRegion = errors.New("config \"dynamodb.region\" is required")
+
+	errMissingTablePrefix = errors.New("table prefix is required")
 )
 
 const (
@@ -35,21 +37,75 @@ type DynamodbConfig struct {
 
 	// Name of the table keeping track of migration history
 	MigrationsTable string `yaml:"migrationsTable"`
+
+	// Prefix prepended to all table names, e.g. "staging_". Default is no prefix.
+	TablePrefix string `yaml:"tablePrefix"`
+
+	// Migrations.Mode is one of "apply" (default), "verify" or "dryRun", see goboot.MigrationsMode.
+	Migrations goboot.MigrationsConfig `yaml:"migrations"`
+
+	// Retry policy of the initial connectivity check.
+	Retry goboot.RetryPolicy `yaml:"retry"`
 }
 
+// TableAccessor contains the table and item operations most applications use.
+type TableAccessor interface {
+	TableExists(ctx context.Context, tableName string) (bool, error)
+	CreateTable(ctx context.Context, tableInput *dynamodb.CreateTableInput) error
+	CreateTableIfNotExists(ctx context.Context, tableInput *dynamodb.CreateTableInput) error
+
+	GetItem(
+		ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options),
+	) (*dynamodb.GetItemOutput, error)
+	PutItem(
+		ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options),
+	) (*dynamodb.PutItemOutput, error)
+	UpdateItem(
+		ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options),
+	) (*dynamodb.UpdateItemOutput, error)
+	DeleteItem(
+		ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options),
+	) (*dynamodb.DeleteItemOutput, error)
+	Query(
+		ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options),
+	) (*dynamodb.QueryOutput, error)
+	Scan(
+		ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options),
+	) (*dynamodb.ScanOutput, error)
+}
+
+var _ TableAccessor = (*DynamoDB)(nil)
+
+// DynamoDB implements the AppService interface.
+//
+// The DynamoDB client is embedded, all item operations can be called on the
+// service directly. Note CreateTable is overridden to wait for the table to
+// become active.
 type DynamoDB struct {
 	MigrationsTable string
 	Migrations      []*Migration
 
-	Client *dynamodb.Client
+	// TablePrefix is prepended to the migrations table and all table names
+	// passed to the table helpers such as CreateTable. When empty the
+	// "dynamodb.tablePrefix" configuration is used.
+	//
+	// Item operations are not prefixed, use TableName to get the full name.
+	TablePrefix string
+
+	// MigrationsMode overrides the "dynamodb.migrations.mode" configuration.
+	MigrationsMode goboot.MigrationsMode
+
+	*dynamodb.Client
 	Config *DynamodbConfig
 
-	log zerolog.Logger
+	log   zerolog.Logger
+	clock goboot.Clock
 }
 
 // Configure connects to DynamoDB.
 func (db *DynamoDB) Configure(env *goboot.AppEnv) error {
 	db.log = env.Log
+	db.clock = env.ClockOrDefault()
 
 	// unmarshal Config and set defaults
 	db.Config = &DynamodbConfig{}
@@ -74,6 +130,21 @@ func (db *DynamoDB) Configure(env *goboot.AppEnv) error {
 		db.Config.MigrationsTable = defaultMigrationsTable
 	}
 
+	if db.TablePrefix == "" {
+		db.TablePrefix = db.Config.TablePrefix
+	}
+
+	if db.MigrationsMode == "" {
+		mode, err := goboot.ParseMigrationsMode(string(db.Config.Migrations.Mode))
+		if err != nil {
+			return fmt.Errorf("parsing \"dynamodb.migrations.mode\": %w", err)
+		}
+
+		db.MigrationsMode = mode
+	}
+
+	db.Config.Retry = db.Config.Retry.WithDefaults()
+
 	if db.Config.Local {
 		client, err := db.createLocalClient(context.Background())
 		if err != nil {
@@ -154,7 +225,15 @@ func (db *DynamoDB) createLocalClient(ctx context.Context) (*dynamodb.Client, er
 }
 
 func (db *DynamoDB) testConnectivity(ctx context.Context) error {
-	_, err := db.Client.ListTables(ctx, &dynamodb.ListTablesInput{})
+	listTables := func(ctx context.Context) error {
+		_, err := db.Client.ListTables(ctx, &dynamodb.ListTablesInput{})
+
+		return err //nolint:wrapcheck // wrapped below
+	}
+
+	err := db.Config.Retry.Retry(ctx, db.clock, listTables, func(err error, wait time.Duration) {
+		db.log.Warn().Err(err).Msgf("failed to connect to DynamoDB, retrying in %s", wait)
+	})
 	if err != nil {
 		return fmt.Errorf("connecting to DynamoDB: %w", err)
 	}
@@ -164,32 +243,47 @@ func (db *DynamoDB) testConnectivity(ctx context.Context) error {
 	return nil
 }
 
+// TableName returns the table name including TablePrefix.
+func (db *DynamoDB) TableName(name string) string {
+	return db.TablePrefix + name
+}
+
+// TableExists returns true if the table exists. The TablePrefix is prepended
+// to tableName.
 func (db *DynamoDB) TableExists(ctx context.Context, tableName string) (bool, error) {
-	tables, err := db.Client.ListTables(ctx, &dynamodb.ListTablesInput{})
-	if err != nil {
-		return false, fmt.Errorf("list tables: %w", err)
-	}
+	p := dynamodb.NewListTablesPaginator(db.Client, &dynamodb.ListTablesInput{})
 
-	for _, n := range tables.TableNames {
-		if n == tableName {
-			return true, nil
+	for p.HasMorePages() {
+		tables, err := p.NextPage(ctx)
+		if err != nil {
+			return false, fmt.Errorf("list tables: %w", err)
+		}
+
+		for _, n := range tables.TableNames {
+			if n == db.TableName(tableName) {
+				return true, nil
+			}
 		}
 	}
 
 	return false, nil
 }
 
-// CreateTable creates a table and waits until it is ready.
+// CreateTable creates a table and waits until it is ready. The TablePrefix is
+// prepended to the table name, tableInput itself is not modified.
 func (db *DynamoDB) CreateTable(ctx context.Context, tableInput *dynamodb.CreateTableInput) error {
-	if _, err := db.Client.CreateTable(ctx, tableInput); err != nil {
-		return fmt.Errorf("creating table %q: %w", *tableInput.TableName, err)
+	input := *tableInput
+	input.TableName = aws.String(db.TableName(*tableInput.TableName))
+
+	if _, err := db.Client.CreateTable(ctx, &input); err != nil {
+		return fmt.Errorf("creating table %q: %w", *input.TableName, err)
 	}
 
-	if err := db.waitForTable(ctx, *tableInput.TableName); err != nil {
+	if err := db.waitForTable(ctx, *input.TableName); err != nil {
 		return err
 	}
 
-	db.log.Info().Msgf("created DynamoDB table %q", *tableInput.TableName)
+	db.log.Info().Msgf("created DynamoDB table %q", *input.TableName)
 
 	return nil
 }
@@ -202,7 +296,7 @@ func (db *DynamoDB) CreateTableIfNotExists(ctx context.Context, tableInput *dyna
 	}
 
 	if exists {
-		db.log.Info().Msgf("table %q already exists, nothing to do here", *tableInput.TableName)
+		db.log.Info().Msgf("table %q already exists, nothing to do here", db.TableName(*tableInput.TableName))
 
 		return nil
 	}
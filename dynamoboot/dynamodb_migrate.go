@@ -9,6 +9,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/nielskrijger/goboot"
 )
 
 type Migration struct {
@@ -22,32 +23,45 @@ type MigrationRecord struct {
 	Duration  int64  `dynamodbav:"duration"`
 }
 
+// Migrate runs all new migrations. In the verify and dry run migrations mode
+// new migrations are reported instead, see goboot.MigrationsMode.
 func (db *DynamoDB) Migrate(ctx context.Context) error {
-	if err := db.CreateTableIfNotExists(ctx, db.createMigrationsTableInput()); err != nil {
-		return err
-	}
+	mode := db.MigrationsMode
+	newMigrations := db.Migrations
 
-	if _, err := db.getMigrations(ctx); err != nil {
-		return err
+	exists := true
+
+	var err error
+
+	if mode == goboot.MigrationsApply {
+		err = db.CreateTableIfNotExists(ctx, db.createMigrationsTableInput())
+	} else {
+		exists, err = db.TableExists(ctx, db.Config.MigrationsTable)
 	}
 
-	newMigrations, err := db.getNewMigrations(ctx)
 	if err != nil {
 		return err
 	}
 
-	if len(db.Migrations) == 0 {
-		db.log.Info().Msg("DynamoDB is up-to-date")
+	if exists {
+		if newMigrations, err = db.getNewMigrations(ctx); err != nil {
+			return err
+		}
+	}
 
-		return nil
+	ids := make([]string, 0, len(newMigrations))
+	for _, migration := range newMigrations {
+		ids = append(ids, migration.ID)
 	}
 
-	return db.runMigrations(ctx, newMigrations)
+	return mode.HandlePending(db.log, "DynamoDB", ids, func() error {
+		return db.runMigrations(ctx, newMigrations)
+	})
 }
 
 func (db *DynamoDB) createMigrationsTableInput() *dynamodb.CreateTableInput {
 	return &dynamodb.CreateTableInput{
-		TableName: aws.String(db.Config.MigrationsTable),
+		TableName: aws.String(db.Config.MigrationsTable), // prefixed by CreateTableIfNotExists
 		AttributeDefinitions: []types.AttributeDefinition{
 			{
 				AttributeName: aws.String("id"),
@@ -113,7 +127,7 @@ func (db *DynamoDB) getNewMigrations(ctx context.Context) ([]*Migration, error)
 // getMigrations retrieves all migrations that have run ordered by timestamp (oldest first).
 func (db *DynamoDB) getMigrations(ctx context.Context) ([]MigrationRecord, error) {
 	p := dynamodb.NewScanPaginator(db.Client, &dynamodb.ScanInput{
-		TableName: aws.String(db.Config.MigrationsTable),
+		TableName: aws.String(db.TableName(db.Config.MigrationsTable)),
 	})
 
 	var items []MigrationRecord
@@ -139,13 +153,13 @@ func (db *DynamoDB) getMigrations(ctx context.Context) ([]MigrationRecord, error
 
 func (db *DynamoDB) runMigrations(ctx context.Context, migrations []*Migration) error {
 	for _, migration := range migrations {
-		start := time.Now()
+		start := db.clock.Now()
 
 		if err := migration.Migrate(db); err != nil {
 			return fmt.Errorf("migration %q failed: %w", migration.ID, err)
 		}
 
-		elapsed := time.Since(start)
+		elapsed := db.clock.Since(start)
 		if err := db.insertMigrationRecord(ctx, migration.ID, elapsed); err != nil {
 			return err
 		}
@@ -157,7 +171,7 @@ func (db *DynamoDB) runMigrations(ctx context.Context, migrations []*Migration)
 func (db *DynamoDB) insertMigrationRecord(ctx context.Context, id string, elapsed time.Duration) error {
 	newRecord := MigrationRecord{
 		ID:        id,
-		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Timestamp: db.clock.Now().UTC().Format(time.RFC3339),
 		Duration:  elapsed.Milliseconds(),
 	}
 
@@ -167,7 +181,7 @@ func (db *DynamoDB) insertMigrationRecord(ctx context.Context, id string, elapse
 	}
 
 	_, err = db.Client.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(db.Config.MigrationsTable),
+		TableName: aws.String(db.TableName(db.Config.MigrationsTable)),
 		Item:      av,
 	})
 	if err != nil {
@@ -9,6 +9,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/nielskrijger/goboot"
 	"github.com/nielskrijger/goboot/dynamoboot"
 	"github.com/stretchr/testify/assert"
 )
@@ -159,3 +160,33 @@ func TestDynamoDBMigrate_ErrorMigrationMissing(t *testing.T) {
 		`running DynamoDB migrations: missing migration "1"; you're not allowed to delete migrations that have already run`, //nolint:lll
 	)
 }
+
+func TestDynamoDBMigrate_VerifyMode(t *testing.T) {
+	db := dynamoboot.NewTestDynamoDB(t, goboot.NewAppEnv("./testdata", "valid"), nil)
+	db.MigrationsMode = goboot.MigrationsVerify
+	db.Migrations = testMigrations
+
+	err := db.Init()
+
+	assert.ErrorIs(t, err, goboot.ErrPendingMigrations)
+	assert.EqualError(t, err, "running DynamoDB migrations: pending migrations: 1, 2")
+}
+
+func TestDynamoDBMigrate_DryRunMode(t *testing.T) {
+	runCount := 0
+	db := dynamoboot.NewTestDynamoDB(t, goboot.NewAppEnv("./testdata", "valid"), nil)
+	db.MigrationsMode = goboot.MigrationsDryRun
+	db.Migrations = []*dynamoboot.Migration{
+		{
+			ID: "1",
+			Migrate: func(db *dynamoboot.DynamoDB) error {
+				runCount++
+
+				return nil
+			},
+		},
+	}
+
+	assert.Nil(t, db.Init())
+	assert.Equal(t, 0, runCount)
+}
@@ -0,0 +1,76 @@
+package dynamoboot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/nielskrijger/goboot"
+	"github.com/nielskrijger/goboot/test"
+)
+
+// NewTestDynamoDB returns a configured and initialized DynamoDB service that
+// prefixes all tables with a unique name. This allows tests to run in parallel
+// against a single DynamoDB instance.
+//
+// Migrations are run using the unique prefix. All tables starting with the
+// prefix are deleted when the test finishes.
+func NewTestDynamoDB(t testing.TB, env *goboot.AppEnv, migrations []*Migration) *DynamoDB {
+	t.Helper()
+
+	db := &DynamoDB{
+		Migrations:  migrations,
+		TablePrefix: test.UniqueName(t) + "_",
+	}
+
+	t.Cleanup(func() {
+		if db.Client == nil {
+			return
+		}
+
+		if err := db.deleteTablesWithPrefix(context.Background()); err != nil {
+			t.Errorf("deleting test tables with prefix %q: %s", db.TablePrefix, err)
+		}
+	})
+
+	if err := db.Configure(env); err != nil {
+		t.Fatalf("configuring DynamoDB: %s", err)
+	}
+
+	if err := db.Init(); err != nil {
+		t.Fatalf("initializing DynamoDB with table prefix %q: %s", db.TablePrefix, err)
+	}
+
+	return db
+}
+
+// deleteTablesWithPrefix deletes all tables starting with TablePrefix.
+func (db *DynamoDB) deleteTablesWithPrefix(ctx context.Context) error {
+	if db.TablePrefix == "" {
+		return errMissingTablePrefix
+	}
+
+	p := dynamodb.NewListTablesPaginator(db.Client, &dynamodb.ListTablesInput{})
+
+	for p.HasMorePages() {
+		tables, err := p.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("list tables: %w", err)
+		}
+
+		for _, n := range tables.TableNames {
+			if !strings.HasPrefix(n, db.TablePrefix) {
+				continue
+			}
+
+			if _, err := db.Client.DeleteTable(ctx, &dynamodb.DeleteTableInput{TableName: aws.String(n)}); err != nil {
+				return fmt.Errorf("deleting table %q: %w", n, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package dynamoboot_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/nielskrijger/goboot"
+	"github.com/nielskrijger/goboot/dynamoboot"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTestDynamoDB_PrefixedTables(t *testing.T) {
+	for _, name := range []string{"first", "second"} {
+		name := name
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			db := dynamoboot.NewTestDynamoDB(t, goboot.NewAppEnv("./testdata", "valid"), testMigrations[:1])
+
+			assert.True(t, strings.HasPrefix(db.TablePrefix, "test_testnewtestdynamodb_prefixedtables_"))
+
+			exists, err := db.TableExists(context.Background(), *testTable)
+			assert.Nil(t, err)
+			assert.True(t, exists)
+
+			exists, err = db.TableExists(context.Background(), db.Config.MigrationsTable)
+			assert.Nil(t, err)
+			assert.True(t, exists)
+
+			_, err = db.DescribeTable(context.Background(), &dynamodb.DescribeTableInput{
+				TableName: aws.String(db.TableName(*testTable)),
+			})
+			assert.Nil(t, err)
+		})
+	}
+}
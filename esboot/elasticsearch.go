@@ -1,6 +1,7 @@
 package esboot
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -8,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"time"
 
 	elasticsearch7 "github.com/elastic/go-elasticsearch/v7"
 	"github.com/elastic/go-elasticsearch/v7/esapi"
@@ -25,14 +27,63 @@ type ESClusterInfo struct {
 	ClusterName string `json:"cluster_name"`
 }
 
+// clusterInfoError is returned when the cluster info request gets a response
+// other than 200 OK.
+type clusterInfoError struct {
+	statusCode int
+	msg        string
+}
+
+func (e *clusterInfoError) Error() string { return e.msg }
+
+// retryable returns true for server errors and rate limiting, other
+// responses such as 401 Unauthorized won't change by retrying.
+func (e *clusterInfoError) retryable() bool {
+	return e.statusCode >= http.StatusInternalServerError || e.statusCode == http.StatusTooManyRequests
+}
+
+// Searcher searches documents in Elasticsearch.
+type Searcher interface {
+	SearchDocuments(ctx context.Context, idx string, query io.Reader, v any) error
+}
+
+// Indexer manages Elasticsearch indices and the documents stored in them.
+type Indexer interface {
+	IndexExists(ctx context.Context, idx string) (bool, error)
+	IndexCreate(ctx context.Context, idx string) error
+	IndexDelete(ctx context.Context, idx string) error
+	IndexDocument(ctx context.Context, idx string, id string, doc any) error
+}
+
+var (
+	_ Searcher = (*Elasticsearch)(nil)
+	_ Indexer  = (*Elasticsearch)(nil)
+)
+
 type Elasticsearch struct {
 	Migrations      []*Migration
 	MigrationsIndex string
 
+	// IndexPrefix is prepended to the migrations index and all index names
+	// passed to the index helpers such as IndexCreate and SearchDocuments.
+	// When empty the "elasticsearch.indexPrefix" configuration is used.
+	//
+	// Requests made with the client directly are not prefixed, use IndexName
+	// to get the full name.
+	IndexPrefix string
+
+	// MigrationsMode overrides the "elasticsearch.migrations.mode" configuration.
+	MigrationsMode goboot.MigrationsMode
+
+	// Retry determines how often the initial connectivity check is retried.
+	// When zero the "elasticsearch.retry" configuration is used.
+	Retry goboot.RetryPolicy
+
 	*elasticsearch7.Client
 	*elasticsearch7.Config
 
-	log zerolog.Logger
+	log   zerolog.Logger
+	clock goboot.Clock
 }
 
 func (s *Elasticsearch) Name() string {
@@ -41,6 +92,7 @@ func (s *Elasticsearch) Name() string {
 
 func (s *Elasticsearch) Configure(env *goboot.AppEnv) error {
 	s.log = env.Log
+	s.clock = env.ClockOrDefault()
 
 	// Fetch config from viper. Avoid unmarshal directly into elasticsearch7.Config
 	// as it doesn't work with env vars:
@@ -63,6 +115,30 @@ func (s *Elasticsearch) Configure(env *goboot.AppEnv) error {
 		}
 	}
 
+	if s.IndexPrefix == "" {
+		s.IndexPrefix = env.Config.GetString("elasticsearch.indexPrefix")
+	}
+
+	if s.MigrationsMode == "" {
+		mode, err := goboot.ParseMigrationsMode(env.Config.GetString("elasticsearch.migrations.mode"))
+		if err != nil {
+			return fmt.Errorf("parsing \"elasticsearch.migrations.mode\": %w", err)
+		}
+
+		s.MigrationsMode = mode
+	}
+
+	if s.Retry == (goboot.RetryPolicy{}) {
+		policy, err := goboot.RetryPolicyFromConfig(env.Config, "elasticsearch.retry")
+		if err != nil {
+			return fmt.Errorf("parsing \"elasticsearch.retry\": %w", err)
+		}
+
+		s.Retry = policy
+	}
+
+	s.Retry = s.Retry.WithDefaults()
+
 	// setup debug logging
 	if env.Log.Debug().Enabled() {
 		human := env.Config.Get("log.human")
@@ -93,9 +169,46 @@ func (s *Elasticsearch) Configure(env *goboot.AppEnv) error {
 }
 
 func (s *Elasticsearch) testConnectivity(env *goboot.AppEnv) error {
+	var (
+		info     ESClusterInfo
+		finalErr error
+	)
+
+	fetchInfo := func(context.Context) (err error) {
+		info, err = s.clusterInfo(env)
+
+		var infoErr *clusterInfoError
+		if errors.As(err, &infoErr) && !infoErr.retryable() {
+			finalErr = err
+
+			return nil
+		}
+
+		return err
+	}
+
+	err := s.Retry.Retry(context.Background(), s.clock, fetchInfo, func(err error, wait time.Duration) {
+		env.Log.Warn().Err(err).Msgf("failed to connect to Elasticsearch, retrying in %s", wait)
+	})
+	if err != nil {
+		return err
+	}
+
+	if finalErr != nil {
+		return finalErr
+	}
+
+	env.Log.Info().Msgf("successfully connected to Elasticsearch cluster \"%s\"", info.ClusterName)
+
+	return nil
+}
+
+func (s *Elasticsearch) clusterInfo(env *goboot.AppEnv) (ESClusterInfo, error) {
+	var info ESClusterInfo
+
 	res, err := s.Client.Info()
 	if err != nil {
-		return fmt.Errorf("fetch Elasticsearch cluster info: %w", err)
+		return info, fmt.Errorf("fetch Elasticsearch cluster info: %w", err)
 	}
 
 	defer func() {
@@ -105,21 +218,21 @@ func (s *Elasticsearch) testConnectivity(env *goboot.AppEnv) error {
 	}()
 
 	if res.StatusCode != http.StatusOK {
-		return fmt.Errorf(
-			"expected 200 OK but got %q while retrieving Elasticsearch info: %s",
-			res.Status(),
-			res.Body,
-		)
+		return info, &clusterInfoError{
+			statusCode: res.StatusCode,
+			msg: fmt.Sprintf(
+				"expected 200 OK but got %q while retrieving Elasticsearch info: %s",
+				res.Status(),
+				res.Body,
+			),
+		}
 	}
 
-	var info ESClusterInfo
 	if err = json.NewDecoder(res.Body).Decode(&info); err != nil {
-		return fmt.Errorf("decoding cluster info: %w", err)
+		return info, fmt.Errorf("decoding cluster info: %w", err)
 	}
 
-	env.Log.Info().Msgf("successfully connected to Elasticsearch cluster \"%s\"", info.ClusterName)
-
-	return nil
+	return info, nil
 }
 
 // Init runs the Elasticsearch migrations.
@@ -135,6 +248,55 @@ func (s *Elasticsearch) Close() error {
 	return nil
 }
 
+// IndexName returns the index name including IndexPrefix.
+func (s *Elasticsearch) IndexName(idx string) string {
+	return s.IndexPrefix + idx
+}
+
+// SearchDocuments runs a search query against specified index and decodes the
+// "_source" of all hits into v.
+//
+// The query is the JSON request body, e.g. `{"query": {"match_all": {}}}`. If
+// query is nil all documents are matched.
+func (s *Elasticsearch) SearchDocuments(ctx context.Context, idx string, query io.Reader, v any) error {
+	req := esapi.SearchRequest{
+		Index: []string{s.IndexName(idx)},
+		Body:  query,
+	}
+
+	res, err := req.Do(ctx, s.Client)
+	if err != nil {
+		return fmt.Errorf("search ES documents in index %q: %w", idx, err)
+	}
+
+	return s.ParseResponse(res, v)
+}
+
+// IndexDocument stores doc as JSON in specified index. When id is empty
+// Elasticsearch generates a document ID.
+//
+// The index is refreshed immediately making the document available for search.
+func (s *Elasticsearch) IndexDocument(ctx context.Context, idx string, id string, doc any) error {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("marshal ES document: %w", err)
+	}
+
+	req := esapi.IndexRequest{
+		Index:      s.IndexName(idx),
+		DocumentID: id,
+		Body:       bytes.NewReader(body),
+		Refresh:    "true",
+	}
+
+	res, err := req.Do(ctx, s.Client)
+	if err != nil {
+		return fmt.Errorf("index ES document in index %q: %w", idx, err)
+	}
+
+	return s.ParseResponse(res, nil)
+}
+
 // ParseResponse decodes the Elasticsearch response body. The response body may
 // contain errors which is why it's advisable to always parse the response even
 // you're not interested in the actual body.
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/elastic/go-elasticsearch/v7/esapi"
+	"github.com/nielskrijger/goboot"
 )
 
 type Migration struct {
@@ -22,32 +23,39 @@ type MigrationRecord struct {
 	Duration  string    `json:"duration"`
 }
 
+// Migrate runs all new migrations. In the verify and dry run MigrationsMode
+// new migrations are reported instead, see goboot.MigrationsMode.
 func (s *Elasticsearch) Migrate(ctx context.Context) error {
 	exists, err := s.IndexExists(ctx, s.MigrationsIndex)
 	if err != nil {
 		return err
 	}
 
-	if !exists {
+	newMigrations := s.Migrations
+
+	switch {
+	case exists:
+		if newMigrations, err = s.getNewMigrations(ctx); err != nil {
+			return err
+		}
+	case s.MigrationsMode == goboot.MigrationsApply:
 		s.log.Info().Msgf("elasticsearch %q index not found; run all migrations", s.MigrationsIndex)
 
 		if err := s.IndexCreate(ctx, s.MigrationsIndex); err != nil {
 			return err
 		}
+	default:
+		s.log.Info().Msgf("elasticsearch %q index not found; all migrations are new", s.MigrationsIndex)
 	}
 
-	newMigrations, err := s.getNewMigrations(ctx)
-	if err != nil {
-		return err
-	}
-
-	if len(s.Migrations) == 0 {
-		s.log.Info().Msg("Elasticsearch is up-to-date")
-
-		return nil
+	ids := make([]string, 0, len(newMigrations))
+	for _, migration := range newMigrations {
+		ids = append(ids, migration.ID)
 	}
 
-	return s.runMigrations(ctx, newMigrations)
+	return s.MigrationsMode.HandlePending(s.log, "Elasticsearch", ids, func() error {
+		return s.runMigrations(ctx, newMigrations)
+	})
 }
 
 // getNewMigrations retrieves the migration history and returns all migrations
@@ -90,13 +98,13 @@ func (s *Elasticsearch) getNewMigrations(ctx context.Context) ([]*Migration, err
 
 func (s *Elasticsearch) runMigrations(ctx context.Context, migrations []*Migration) error {
 	for _, migration := range migrations {
-		start := time.Now()
+		start := s.clock.Now()
 
 		if err := migration.Migrate(s); err != nil {
 			return fmt.Errorf("migration %q failed: %w", migration.ID, err)
 		}
 
-		elapsed := time.Since(start)
+		elapsed := s.clock.Since(start)
 		if err := s.InsertMigrationRecord(ctx, migration.ID, elapsed); err != nil {
 			return err
 		}
@@ -108,7 +116,7 @@ func (s *Elasticsearch) runMigrations(ctx context.Context, migrations []*Migrati
 func (s *Elasticsearch) InsertMigrationRecord(ctx context.Context, id string, elapsed time.Duration) error {
 	newRecord, err := json.Marshal(MigrationRecord{
 		ID:        id,
-		Timestamp: time.Now().UTC(),
+		Timestamp: s.clock.Now().UTC(),
 		Duration:  elapsed.Truncate(time.Millisecond).String(),
 	})
 	if err != nil {
@@ -116,7 +124,7 @@ func (s *Elasticsearch) InsertMigrationRecord(ctx context.Context, id string, el
 	}
 
 	req := &esapi.IndexRequest{
-		Index:      s.MigrationsIndex,
+		Index:      s.IndexName(s.MigrationsIndex),
 		DocumentID: id,
 		Body:       bytes.NewReader(newRecord),
 		Refresh:    "true",
@@ -129,7 +137,10 @@ func (s *Elasticsearch) InsertMigrationRecord(ctx context.Context, id string, el
 	return nil
 }
 
+// IndexExists returns true if the index exists. The IndexPrefix is prepended
+// to idx.
 func (s *Elasticsearch) IndexExists(ctx context.Context, idx string) (bool, error) {
+	idx = s.IndexName(idx)
 	req := esapi.IndicesExistsRequest{
 		Index: []string{idx},
 	}
@@ -142,7 +153,9 @@ func (s *Elasticsearch) IndexExists(ctx context.Context, idx string) (bool, erro
 	return res.StatusCode == http.StatusOK, nil
 }
 
+// IndexCreate creates an index. The IndexPrefix is prepended to idx.
 func (s *Elasticsearch) IndexCreate(ctx context.Context, idx string) error {
+	idx = s.IndexName(idx)
 	req := esapi.IndicesCreateRequest{Index: idx}
 
 	res, err := req.Do(ctx, s.Client)
@@ -163,7 +176,10 @@ func (s *Elasticsearch) IndexCreate(ctx context.Context, idx string) error {
 	return nil
 }
 
+// IndexDelete deletes an index if it exists. The IndexPrefix is prepended
+// to idx.
 func (s *Elasticsearch) IndexDelete(ctx context.Context, idx string) error {
+	idx = s.IndexName(idx)
 	req := esapi.IndicesDeleteRequest{
 		Index:             []string{idx},
 		IgnoreUnavailable: esapi.BoolPtr(true),
@@ -189,13 +205,14 @@ func (s *Elasticsearch) IndexDelete(ctx context.Context, idx string) error {
 
 // getMigrations retrieves all migrations that have run.
 func (s *Elasticsearch) getMigrations(ctx context.Context, r any) error {
+	idx := s.IndexName(s.MigrationsIndex)
 	req := esapi.SearchRequest{
-		Index: []string{s.MigrationsIndex},
+		Index: []string{idx},
 	}
 
 	res, err := req.Do(ctx, s.Client)
 	if err != nil {
-		return fmt.Errorf("search all ES documents in index %q: %w", s.MigrationsIndex, err)
+		return fmt.Errorf("search all ES documents in index %q: %w", idx, err)
 	}
 
 	if res.StatusCode != http.StatusOK {
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/elastic/go-elasticsearch/v7/esapi"
+	"github.com/nielskrijger/goboot"
 	"github.com/nielskrijger/goboot/esboot"
 	"github.com/stretchr/testify/assert"
 	"github.com/tidwall/gjson"
@@ -130,3 +131,36 @@ func TestElasticsearchMigrate_ErrorMigrationMissing(t *testing.T) {
 		`running Elasticsearch migrations: missing migration "1"; you're not allowed to delete migrations that have already run`, //nolint:lll
 	)
 }
+
+func TestElasticsearchMigrate_VerifyMode(t *testing.T) {
+	s := esboot.NewTestElasticsearch(t, goboot.NewAppEnv("./testdata", "valid"), nil)
+	s.MigrationsMode = goboot.MigrationsVerify
+	s.Migrations = []*esboot.Migration{
+		{ID: "1", Migrate: func(es *esboot.Elasticsearch) error { return nil }},
+		{ID: "2", Migrate: func(es *esboot.Elasticsearch) error { return nil }},
+	}
+
+	err := s.Init()
+
+	assert.ErrorIs(t, err, goboot.ErrPendingMigrations)
+	assert.EqualError(t, err, "running Elasticsearch migrations: pending migrations: 1, 2")
+}
+
+func TestElasticsearchMigrate_DryRunMode(t *testing.T) {
+	runCount := 0
+	s := esboot.NewTestElasticsearch(t, goboot.NewAppEnv("./testdata", "valid"), nil)
+	s.MigrationsMode = goboot.MigrationsDryRun
+	s.Migrations = []*esboot.Migration{
+		{
+			ID: "1",
+			Migrate: func(es *esboot.Elasticsearch) error {
+				runCount++
+
+				return nil
+			},
+		},
+	}
+
+	assert.Nil(t, s.Init())
+	assert.Equal(t, 0, runCount)
+}
@@ -2,10 +2,13 @@ package esboot_test
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/nielskrijger/goboot"
 	"github.com/nielskrijger/goboot/esboot"
+	"github.com/nielskrijger/goboot/test"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -46,7 +49,25 @@ func TestElasticsearch_ErrorNoAddresses(t *testing.T) {
 }
 
 func TestElasticsearch_ErrorOnConnect(t *testing.T) {
+	clock := test.NewAutoAdvancingFakeClock(time.Now())
+	env := goboot.NewAppEnv("./testdata", "invalid-password")
+	env.Clock = clock
+
 	s := &esboot.Elasticsearch{}
-	err := s.Configure(goboot.NewAppEnv("./testdata", "invalid-password"))
+	err := s.Configure(env)
 	assert.Contains(t, err.Error(), "expected 200 OK but got \"401 Unauthorized\" while retrieving Elasticsearch info")
+	assert.Empty(t, clock.Sleeps()) // a 401 is not retried
+}
+
+func TestElasticsearch_IndexAndSearchDocuments(t *testing.T) {
+	s := &esboot.Elasticsearch{}
+	setupElasticsearchEnv(t, s)
+
+	ctx := context.Background()
+	assert.Nil(t, s.IndexCreate(ctx, "test"))
+	assert.Nil(t, s.IndexDocument(ctx, "test", "1", map[string]string{"foo": "bar"}))
+
+	var docs []map[string]string
+	assert.Nil(t, s.SearchDocuments(ctx, "test", strings.NewReader(`{"query": {"match_all": {}}}`), &docs))
+	assert.Equal(t, []map[string]string{{"foo": "bar"}}, docs)
 }
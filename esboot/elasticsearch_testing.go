@@ -0,0 +1,44 @@
+package esboot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nielskrijger/goboot"
+	"github.com/nielskrijger/goboot/test"
+)
+
+// NewTestElasticsearch returns a configured and initialized Elasticsearch
+// service that prefixes all indices with a unique name. This allows tests to
+// run in parallel against a single Elasticsearch cluster.
+//
+// Migrations are run using the unique prefix. All indices starting with the
+// prefix are deleted when the test finishes.
+func NewTestElasticsearch(t testing.TB, env *goboot.AppEnv, migrations []*Migration) *Elasticsearch {
+	t.Helper()
+
+	s := &Elasticsearch{
+		Migrations:  migrations,
+		IndexPrefix: test.UniqueName(t) + "_",
+	}
+
+	t.Cleanup(func() {
+		if s.Client == nil {
+			return
+		}
+
+		if err := s.IndexDelete(context.Background(), "*"); err != nil {
+			t.Errorf("deleting test indices %q: %s", s.IndexName("*"), err)
+		}
+	})
+
+	if err := s.Configure(env); err != nil {
+		t.Fatalf("configuring Elasticsearch: %s", err)
+	}
+
+	if err := s.Init(); err != nil {
+		t.Fatalf("initializing Elasticsearch with index prefix %q: %s", s.IndexPrefix, err)
+	}
+
+	return s
+}
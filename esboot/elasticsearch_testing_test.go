@@ -0,0 +1,43 @@
+package esboot_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nielskrijger/goboot"
+	"github.com/nielskrijger/goboot/esboot"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTestElasticsearch_PrefixedIndices(t *testing.T) {
+	for _, name := range []string{"first", "second"} {
+		name := name
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			s := esboot.NewTestElasticsearch(t, goboot.NewAppEnv("./testdata", "valid"), []*esboot.Migration{
+				{
+					ID: "1",
+					Migrate: func(es *esboot.Elasticsearch) error {
+						return es.IndexCreate(context.Background(), "test") //nolint:wrapcheck
+					},
+				},
+			})
+
+			assert.True(t, strings.HasPrefix(s.IndexPrefix, "test_testnewtestelasticsearch_prefixedin"))
+
+			ctx := context.Background()
+			assert.Nil(t, s.IndexDocument(ctx, "test", "1", map[string]string{"name": name}))
+
+			var docs []map[string]string
+			assert.Nil(t, s.SearchDocuments(ctx, "test", nil, &docs))
+			assert.Equal(t, []map[string]string{{"name": name}}, docs)
+
+			exists, err := s.IndexExists(ctx, s.MigrationsIndex)
+			assert.Nil(t, err)
+			assert.True(t, exists)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package goboot
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// MigrationsMode determines what services do with their migrations on Init.
+type MigrationsMode string
+
+const (
+	// MigrationsApply runs all pending migrations. This is the default.
+	MigrationsApply MigrationsMode = "apply"
+
+	// MigrationsVerify fails Init if any migration is pending, e.g. when
+	// migrations are applied by a separate job.
+	MigrationsVerify MigrationsMode = "verify"
+
+	// MigrationsDryRun logs pending migrations without applying them.
+	MigrationsDryRun MigrationsMode = "dryRun"
+)
+
+// ErrPendingMigrations is returned by Init in MigrationsVerify mode when
+// migrations have not been applied yet.
+var ErrPendingMigrations = errors.New("pending migrations")
+
+var errInvalidMigrationsMode = errors.New("invalid migrations mode")
+
+// PendingMigrationsError returns ErrPendingMigrations listing the ids of the
+// pending migrations.
+func PendingMigrationsError(ids []string) error {
+	return fmt.Errorf("%w: %s", ErrPendingMigrations, strings.Join(ids, ", "))
+}
+
+// ParseMigrationsMode parses a "migrations.mode" configuration value. An
+// empty value returns MigrationsApply.
+func ParseMigrationsMode(mode string) (MigrationsMode, error) {
+	switch MigrationsMode(mode) {
+	case "":
+		return MigrationsApply, nil
+	case MigrationsApply, MigrationsVerify, MigrationsDryRun:
+		return MigrationsMode(mode), nil
+	default:
+		return "", fmt.Errorf("%w %q; must be %q, %q or %q",
+			errInvalidMigrationsMode, mode, MigrationsApply, MigrationsVerify, MigrationsDryRun)
+	}
+}
+
+// MigrationsConfig contains the "migrations" settings of a service.
+type MigrationsConfig struct {
+	Mode MigrationsMode `yaml:"mode"`
+}
+
+// HandlePending calls apply to run the pending migrations with the specified
+// ids in MigrationsApply mode. In MigrationsVerify mode it returns a
+// PendingMigrationsError and in MigrationsDryRun mode the migrations are only
+// logged. The service name is used in log messages, e.g. "DynamoDB".
+func (m MigrationsMode) HandlePending(log zerolog.Logger, service string, ids []string, apply func() error) error {
+	if len(ids) == 0 {
+		log.Info().Msgf("%s is up-to-date", service)
+
+		return nil
+	}
+
+	switch m {
+	case MigrationsVerify:
+		return PendingMigrationsError(ids)
+	case MigrationsDryRun:
+		for _, id := range ids {
+			log.Info().Msgf("pending %s migration %q", service, id)
+		}
+
+		log.Info().Msgf("dry run; %d pending %s migrations were not applied", len(ids), service)
+
+		return nil
+	default:
+		return apply()
+	}
+}
@@ -0,0 +1,53 @@
+package goboot_test
+
+import (
+	"testing"
+
+	"github.com/nielskrijger/goboot"
+	"github.com/nielskrijger/goboot/test"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrationsMode_Parse(t *testing.T) {
+	for value, expected := range map[string]goboot.MigrationsMode{
+		"":       goboot.MigrationsApply,
+		"apply":  goboot.MigrationsApply,
+		"verify": goboot.MigrationsVerify,
+		"dryRun": goboot.MigrationsDryRun,
+	} {
+		mode, err := goboot.ParseMigrationsMode(value)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, mode)
+	}
+}
+
+func TestMigrationsMode_ErrorInvalid(t *testing.T) {
+	_, err := goboot.ParseMigrationsMode("skip")
+
+	assert.EqualError(t, err, `invalid migrations mode "skip"; must be "apply", "verify" or "dryRun"`)
+}
+
+func TestMigrationsMode_HandlePending(t *testing.T) {
+	applied := 0
+	apply := func() error {
+		applied++
+
+		return nil
+	}
+	log := &test.Logger{}
+
+	assert.Nil(t, goboot.MigrationsApply.HandlePending(zerolog.New(log), "Test", []string{"1", "2"}, apply))
+	assert.Equal(t, 1, applied)
+
+	err := goboot.MigrationsVerify.HandlePending(zerolog.New(log), "Test", []string{"1", "2"}, apply)
+	assert.ErrorIs(t, err, goboot.ErrPendingMigrations)
+	assert.EqualError(t, err, "pending migrations: 1, 2")
+
+	assert.Nil(t, goboot.MigrationsDryRun.HandlePending(zerolog.New(log), "Test", []string{"1", "2"}, apply))
+	assert.Equal(t, "dry run; 2 pending Test migrations were not applied", log.LastLine()["message"])
+
+	assert.Nil(t, goboot.MigrationsVerify.HandlePending(zerolog.New(log), "Test", nil, apply))
+	assert.Equal(t, "Test is up-to-date", log.LastLine()["message"])
+	assert.Equal(t, 1, applied)
+}
@@ -0,0 +1,431 @@
+package pgboot
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/nielskrijger/goboot"
+)
+
+const (
+	defaultJobsTable        = "jobs"
+	defaultJobsWorkers      = 10
+	defaultJobsPollInterval = time.Second
+	defaultJobsLease        = 5 * time.Minute
+)
+
+// Job statuses stored in the jobs table.
+const (
+	JobPending = "pending"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+var (
+	errJobQueueStarted = errors.New("job queue has already been started")
+	errNoJobHandlers   = errors.New("job queue has no handlers")
+	errNoJobHandler    = errors.New("no handler registered for job kind")
+	errJobLeaseExpired = errors.New("job lease expired")
+)
+
+// Job is a job claimed by a JobQueue worker.
+type Job struct {
+	ID       int64
+	Kind     string
+	Payload  json.RawMessage
+	Attempt  int // starts at 1 for the first attempt
+	RunAt    time.Time
+	Enqueued time.Time
+}
+
+// JobHandler processes a job. It runs outside of a transaction, so it holds
+// no locks or connections while it runs; use Postgres.WithTx for changes that
+// must be atomic. ctx is cancelled when the lease of the job expires.
+type JobHandler func(ctx context.Context, job *Job) error
+
+// JobQueue runs background jobs stored in Postgres. Jobs are enqueued in the
+// same transaction as the changes that trigger them and claimed by workers
+// with FOR UPDATE SKIP LOCKED, so multiple processes can work on the same
+// queue without a separate broker.
+//
+// A claimed job is marked running for the duration of the Lease and the
+// claim is committed before the handler runs. Jobs whose lease expired, e.g.
+// because the process stopped, are claimed again. Handlers may therefore run
+// more than once for the same job and should be idempotent.
+//
+// A failing job is retried with exponential backoff until the attempts of
+// the Retry policy are exhausted, after which the job is marked failed.
+//
+// Add the migration returned by JobQueue.Migration to Postgres.Migrations to
+// create the jobs table.
+type JobQueue struct {
+	Postgres *Postgres
+
+	Table        string        // default is "jobs"
+	Workers      int           // number of jobs processed concurrently, default is 10
+	PollInterval time.Duration // time between polls when there are no jobs due, default is 1 second
+	Lease        time.Duration // time a job may run before it is claimed again, default is 5 minutes
+
+	// Retry determines the backoff between attempts of a failing job and the
+	// maximum number of attempts. Default is goboot.RetryPolicy defaults.
+	Retry goboot.RetryPolicy
+
+	handlers map[string]JobHandler
+
+	mu      sync.Mutex
+	stop    context.CancelFunc
+	workers sync.WaitGroup
+}
+
+// jobRow is a row of the jobs table.
+type jobRow struct {
+	ID        int64     `db:"id"`
+	Kind      string    `db:"kind"`
+	Payload   string    `db:"payload"`
+	Attempts  int       `db:"attempts"`
+	RunAt     time.Time `db:"run_at"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// Migration returns a Go migration that creates the jobs table.
+func (q *JobQueue) Migration(version uint) *Migration {
+	return &Migration{
+		Version: version,
+		ID:      "create_" + q.table(),
+		Migrate: func(ctx context.Context, tx *sqlx.Tx) error {
+			table := q.quotedTable()
+			_, err := tx.ExecContext(ctx, `
+				CREATE TABLE `+table+` (
+					id BIGSERIAL PRIMARY KEY,
+					kind TEXT NOT NULL,
+					payload JSONB NOT NULL,
+					status TEXT NOT NULL DEFAULT 'pending',
+					attempts INT NOT NULL DEFAULT 0,
+					run_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+					locked_until TIMESTAMPTZ,
+					created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+					finished_at TIMESTAMPTZ,
+					last_error TEXT
+				);
+				CREATE INDEX `+quoteIdentifier(q.table()+"_pending_idx")+` ON `+table+` (run_at, id)
+					WHERE status = 'pending';
+				CREATE INDEX `+quoteIdentifier(q.table()+"_running_idx")+` ON `+table+` (locked_until)
+					WHERE status = 'running';`,
+			)
+
+			return err //nolint:wrapcheck // wrapped by runGoMigration
+		},
+		Rollback: func(ctx context.Context, tx *sqlx.Tx) error {
+			_, err := tx.ExecContext(ctx, `DROP TABLE `+q.quotedTable())
+
+			return err //nolint:wrapcheck // wrapped by runGoMigration
+		},
+	}
+}
+
+// Handle registers the handler of jobs of the specified kind, e.g.
+// "send_welcome_email". Register all handlers before calling Start.
+func (q *JobQueue) Handle(kind string, handler JobHandler) {
+	if q.handlers == nil {
+		q.handlers = make(map[string]JobHandler)
+	}
+
+	q.handlers[kind] = handler
+}
+
+// Enqueue adds a job within tx. The job runs at runAt, or as soon as
+// possible when runAt is zero, after tx has been committed.
+func (q *JobQueue) Enqueue(ctx context.Context, tx *sqlx.Tx, kind string, payload any, runAt time.Time) error {
+	bytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshalling payload of %q job: %w", kind, err)
+	}
+
+	if runAt.IsZero() {
+		runAt = q.Postgres.clock.Now()
+	}
+
+	query := `INSERT INTO ` + q.quotedTable() + ` (kind, payload, run_at) VALUES ($1, $2, $3)`
+	if _, err := tx.ExecContext(ctx, query, kind, string(bytes), runAt); err != nil {
+		return fmt.Errorf("enqueueing %q job: %w", kind, err)
+	}
+
+	return nil
+}
+
+// Start starts the workers, which process jobs until Close is called or ctx
+// is cancelled. Jobs running when ctx is cancelled receive the cancelled
+// context, unlike jobs running when Close is called.
+//
+// The Postgres service closes the job queue when it is closed itself.
+func (q *JobQueue) Start(ctx context.Context) error {
+	if len(q.handlers) == 0 {
+		return errNoJobHandlers
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.stop != nil {
+		return errJobQueueStarted
+	}
+
+	stopCtx, stop := context.WithCancel(ctx)
+	q.stop = stop
+
+	for i := 0; i < q.workerCount(); i++ {
+		q.workers.Add(1)
+
+		go q.work(ctx, stopCtx)
+	}
+
+	q.Postgres.addJobQueue(q)
+
+	q.Postgres.log.Info().Int("workers", q.workerCount()).Msgf("started %s job queue", q.table())
+
+	return nil
+}
+
+// Close stops claiming new jobs and waits for the running jobs to finish.
+func (q *JobQueue) Close() error {
+	q.mu.Lock()
+	stop := q.stop
+	q.mu.Unlock()
+
+	if stop == nil {
+		return nil
+	}
+
+	stop()
+	q.workers.Wait()
+
+	return nil
+}
+
+// work runs jobs until stop is done, polling when there are no jobs due.
+func (q *JobQueue) work(ctx context.Context, stop context.Context) {
+	defer q.workers.Done()
+
+	for stop.Err() == nil {
+		ran, err := q.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			q.Postgres.log.Error().Err(err).Msgf("failed to run %s job", q.table())
+		}
+
+		if ran && err == nil {
+			continue
+		}
+
+		select {
+		case <-stop.Done():
+		case <-q.Postgres.clock.After(q.pollInterval()):
+		}
+	}
+}
+
+// RunOnce claims a single job that is due, or whose lease expired, and runs
+// it. Returns false when no job was due.
+func (q *JobQueue) RunOnce(ctx context.Context) (bool, error) {
+	job, err := q.claim(ctx)
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	// a job that was claimed again after its last attempt never finished
+	if job.Attempt > q.Retry.Attempts() {
+		return true, q.finish(ctx, job, errJobLeaseExpired)
+	}
+
+	handlerCtx, cancel := context.WithTimeout(ctx, q.lease())
+	defer cancel()
+
+	return true, q.finish(ctx, job, q.handle(handlerCtx, job))
+}
+
+// claim marks a job that is due as running until its lease expires and
+// commits, so no lock is held while the job runs. Returns nil when no job
+// was due.
+func (q *JobQueue) claim(ctx context.Context) (*Job, error) {
+	var job *Job
+
+	err := q.Postgres.WithTx(ctx, nil, func(tx *sqlx.Tx) error {
+		var row jobRow
+
+		now := q.Postgres.clock.Now()
+		query := `UPDATE ` + q.quotedTable() + `
+			SET status = $1, attempts = attempts + 1, locked_until = $2
+			WHERE id = (
+				SELECT id FROM ` + q.quotedTable() + `
+				WHERE (status = $3 AND run_at <= $4) OR (status = $1 AND locked_until <= $4)
+				ORDER BY run_at, id
+				LIMIT 1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, kind, payload, attempts, run_at, created_at`
+
+		err := tx.GetContext(ctx, &row, query, JobRunning, now.Add(q.lease()), JobPending, now)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("claiming job: %w", err)
+		}
+
+		job = &Job{
+			ID:       row.ID,
+			Kind:     row.Kind,
+			Payload:  json.RawMessage(row.Payload),
+			Attempt:  row.Attempts,
+			RunAt:    row.RunAt,
+			Enqueued: row.CreatedAt,
+		}
+
+		return nil
+	})
+
+	return job, err
+}
+
+// handle calls the handler of job, turning a panic into an error so a
+// misbehaving handler doesn't stop the worker.
+func (q *JobQueue) handle(ctx context.Context, job *Job) (err error) {
+	handler, ok := q.handlers[job.Kind]
+	if !ok {
+		return fmt.Errorf("%w %q", errNoJobHandler, job.Kind)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("job panicked: %v", p) //nolint:goerr113
+		}
+	}()
+
+	return handler(ctx, job)
+}
+
+// finish marks job done, or schedules a retry or marks it failed when jobErr
+// is set. Nothing is recorded when the job was claimed again by another
+// worker after its lease expired.
+func (q *JobQueue) finish(ctx context.Context, job *Job, jobErr error) error {
+	now := q.Postgres.clock.Now()
+	status := JobDone
+	runAt := job.RunAt
+	finishedAt := &now
+
+	var lastError *string
+
+	if jobErr != nil {
+		status, runAt, finishedAt = q.retry(job, jobErr, now)
+		msg := jobErr.Error()
+		lastError = &msg
+	}
+
+	query := `UPDATE ` + q.quotedTable() + `
+		SET status = $2, locked_until = NULL, run_at = $3, finished_at = $4, last_error = $5
+		WHERE id = $1 AND status = $6 AND attempts = $7`
+
+	res, err := q.Postgres.DB.ExecContext(
+		ctx, query, job.ID, status, runAt, finishedAt, lastError, JobRunning, job.Attempt,
+	)
+	if err != nil {
+		return fmt.Errorf("recording result of job %d: %w", job.ID, err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		q.Postgres.log.Warn().
+			Int64("id", job.ID).
+			Int("attempt", job.Attempt).
+			Msgf("%q job was claimed again after its lease expired, discarding its result", job.Kind)
+	}
+
+	return nil
+}
+
+// retry returns the status, next run and finish time of a failed job, which
+// is retried unless it is out of attempts.
+func (q *JobQueue) retry(job *Job, jobErr error, now time.Time) (string, time.Time, *time.Time) {
+	log := q.Postgres.log.Warn()
+	status := JobPending
+	runAt := job.RunAt
+
+	var finishedAt *time.Time
+
+	if job.Attempt >= q.Retry.Attempts() {
+		log = q.Postgres.log.Error()
+		status = JobFailed
+		finishedAt = &now
+	} else {
+		runAt = now.Add(q.Retry.Backoff(job.Attempt))
+	}
+
+	log.Err(jobErr).
+		Int64("id", job.ID).
+		Int("attempt", job.Attempt).
+		Str("status", status).
+		Time("runAt", runAt).
+		Msgf("%q job failed", job.Kind)
+
+	return status, runAt, finishedAt
+}
+
+func (q *JobQueue) table() string {
+	if q.Table == "" {
+		return defaultJobsTable
+	}
+
+	return q.Table
+}
+
+func (q *JobQueue) quotedTable() string {
+	return quoteIdentifier(q.table())
+}
+
+func (q *JobQueue) workerCount() int {
+	if q.Workers <= 0 {
+		return defaultJobsWorkers
+	}
+
+	return q.Workers
+}
+
+func (q *JobQueue) lease() time.Duration {
+	if q.Lease <= 0 {
+		return defaultJobsLease
+	}
+
+	return q.Lease
+}
+
+func (q *JobQueue) pollInterval() time.Duration {
+	if q.PollInterval <= 0 {
+		return defaultJobsPollInterval
+	}
+
+	return q.PollInterval
+}
+
+// addJobQueue registers q to be closed when the service is closed.
+func (s *Postgres) addJobQueue(q *JobQueue) {
+	s.jobQueuesMu.Lock()
+	defer s.jobQueuesMu.Unlock()
+
+	s.jobQueues = append(s.jobQueues, q)
+}
+
+// closeJobQueues drains the job queues started on the service.
+func (s *Postgres) closeJobQueues() {
+	s.jobQueuesMu.Lock()
+	queues := s.jobQueues
+	s.jobQueues = nil
+	s.jobQueuesMu.Unlock()
+
+	for _, q := range queues {
+		_ = q.Close()
+	}
+}
@@ -0,0 +1,211 @@
+package pgboot_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/nielskrijger/goboot"
+	"github.com/nielskrijger/goboot/pgboot"
+	"github.com/nielskrijger/goboot/test"
+	"github.com/stretchr/testify/assert"
+)
+
+const selectJob = "SELECT status, attempts, last_error FROM jobs"
+
+type jobRow struct {
+	Status    string  `db:"status"`
+	Attempts  int     `db:"attempts"`
+	LastError *string `db:"last_error"`
+}
+
+func newTestJobQueue(t *testing.T, clock goboot.Clock) *pgboot.JobQueue {
+	t.Helper()
+
+	queue := &pgboot.JobQueue{
+		Retry: goboot.RetryPolicy{MaxRetries: 1, InitialInterval: time.Second, Jitter: -1},
+	}
+	queue.Postgres = newTestPostgresWithClock(t, clock, "./testdata/migrations", queue.Migration(100))
+
+	return queue
+}
+
+func enqueueTestJob(t *testing.T, queue *pgboot.JobQueue, kind string) {
+	t.Helper()
+
+	err := queue.Postgres.WithTx(context.Background(), nil, func(tx *sqlx.Tx) error {
+		return queue.Enqueue(context.Background(), tx, kind, map[string]any{"name": "Third record"}, time.Time{})
+	})
+	assert.Nil(t, err)
+}
+
+func insertRecordJob(queue *pgboot.JobQueue) pgboot.JobHandler {
+	return func(ctx context.Context, job *pgboot.Job) error {
+		var payload struct {
+			Name string `json:"name"`
+		}
+
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		_, err := queue.Postgres.DB.ExecContext(ctx, "INSERT INTO test_table (name) VALUES ($1)", payload.Name)
+
+		return err //nolint:wrapcheck
+	}
+}
+
+// crashTestJob marks the enqueued job as running like a worker that stopped
+// during the specified attempt, whose lease expires at lockedUntil.
+func crashTestJob(t *testing.T, queue *pgboot.JobQueue, attempt int, lockedUntil time.Time) {
+	t.Helper()
+
+	_, err := queue.Postgres.DB.Exec(
+		"UPDATE jobs SET status = $1, attempts = $2, locked_until = $3",
+		pgboot.JobRunning, attempt, lockedUntil,
+	)
+	assert.Nil(t, err)
+}
+
+func TestJobQueue_RunOnce(t *testing.T) {
+	queue := newTestJobQueue(t, test.NewFakeClock(time.Now()))
+	queue.Handle("insert_record", insertRecordJob(queue))
+	enqueueTestJob(t, queue, "insert_record")
+
+	ran, err := queue.RunOnce(context.Background())
+	assert.Nil(t, err)
+	assert.True(t, ran)
+	assertRow(t, queue.Postgres, jobRow{Status: pgboot.JobDone, Attempts: 1}, selectJob)
+	assert.Equal(t, 3, countRecords(t, queue.Postgres))
+
+	// done jobs are not run again
+	ran, err = queue.RunOnce(context.Background())
+	assert.Nil(t, err)
+	assert.False(t, ran)
+}
+
+func TestJobQueue_RetryWithBackoffUntilFailed(t *testing.T) {
+	clock := test.NewFakeClock(time.Now())
+	queue := newTestJobQueue(t, clock)
+	queue.Handle("insert_record", func(ctx context.Context, job *pgboot.Job) error {
+		return errors.New("unavailable")
+	})
+	enqueueTestJob(t, queue, "insert_record")
+
+	ran, err := queue.RunOnce(context.Background())
+	assert.Nil(t, err)
+	assert.True(t, ran)
+
+	lastError := "unavailable"
+	assertRow(t, queue.Postgres, jobRow{Status: pgboot.JobPending, Attempts: 1, LastError: &lastError}, selectJob)
+
+	// the retry is not due until the backoff has passed
+	ran, err = queue.RunOnce(context.Background())
+	assert.Nil(t, err)
+	assert.False(t, ran)
+
+	clock.Advance(time.Second)
+
+	ran, err = queue.RunOnce(context.Background())
+	assert.Nil(t, err)
+	assert.True(t, ran)
+	assertRow(t, queue.Postgres, jobRow{Status: pgboot.JobFailed, Attempts: 2, LastError: &lastError}, selectJob)
+}
+
+func TestJobQueue_ClaimAgainWhenLeaseExpired(t *testing.T) {
+	clock := test.NewFakeClock(time.Now())
+	queue := newTestJobQueue(t, clock)
+	queue.Handle("insert_record", insertRecordJob(queue))
+	enqueueTestJob(t, queue, "insert_record")
+	crashTestJob(t, queue, 1, clock.Now().Add(time.Minute))
+
+	// the job is not claimed again until its lease expired
+	ran, err := queue.RunOnce(context.Background())
+	assert.Nil(t, err)
+	assert.False(t, ran)
+
+	clock.Advance(time.Minute)
+
+	ran, err = queue.RunOnce(context.Background())
+	assert.Nil(t, err)
+	assert.True(t, ran)
+	assertRow(t, queue.Postgres, jobRow{Status: pgboot.JobDone, Attempts: 2}, selectJob)
+	assert.Equal(t, 3, countRecords(t, queue.Postgres))
+}
+
+func TestJobQueue_FailWhenLeaseOfLastAttemptExpired(t *testing.T) {
+	clock := test.NewFakeClock(time.Now())
+	queue := newTestJobQueue(t, clock)
+	queue.Handle("insert_record", insertRecordJob(queue))
+	enqueueTestJob(t, queue, "insert_record")
+	crashTestJob(t, queue, 2, clock.Now())
+
+	ran, err := queue.RunOnce(context.Background())
+	assert.Nil(t, err)
+	assert.True(t, ran)
+
+	lastError := "job lease expired"
+	assertRow(t, queue.Postgres, jobRow{Status: pgboot.JobFailed, Attempts: 3, LastError: &lastError}, selectJob)
+	assert.Equal(t, 2, countRecords(t, queue.Postgres)) // the handler didn't run
+}
+
+func TestJobQueue_FailWithoutHandler(t *testing.T) {
+	queue := newTestJobQueue(t, test.NewFakeClock(time.Now()))
+	queue.Handle("insert_record", insertRecordJob(queue))
+	enqueueTestJob(t, queue, "unknown")
+
+	ran, err := queue.RunOnce(context.Background())
+	assert.Nil(t, err)
+	assert.True(t, ran)
+
+	lastError := `no handler registered for job kind "unknown"`
+	assertRow(t, queue.Postgres, jobRow{Status: pgboot.JobPending, Attempts: 1, LastError: &lastError}, selectJob)
+}
+
+func TestJobQueue_DrainOnClose(t *testing.T) {
+	queue := newTestJobQueue(t, goboot.SystemClock{})
+	started := make(chan struct{})
+	release := make(chan struct{})
+	queue.Handle("insert_record", func(ctx context.Context, job *pgboot.Job) error {
+		close(started)
+		<-release
+
+		return insertRecordJob(queue)(ctx, job)
+	})
+	enqueueTestJob(t, queue, "insert_record")
+
+	assert.Nil(t, queue.Start(context.Background()))
+	<-started
+
+	closed := make(chan struct{})
+
+	go func() {
+		assert.Nil(t, queue.Close())
+		close(closed)
+	}()
+
+	assert.Never(t, func() bool { return isClosed(closed) }, 50*time.Millisecond, 10*time.Millisecond)
+
+	close(release)
+
+	assert.Eventually(t, func() bool { return isClosed(closed) }, time.Second, 10*time.Millisecond)
+	assertRow(t, queue.Postgres, jobRow{Status: pgboot.JobDone, Attempts: 1}, selectJob)
+}
+
+func TestJobQueue_ErrorStartWithoutHandlers(t *testing.T) {
+	queue := &pgboot.JobQueue{}
+
+	assert.EqualError(t, queue.Start(context.Background()), "job queue has no handlers")
+}
+
+func isClosed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,277 @@
+package pgboot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/nielskrijger/goboot"
+)
+
+const (
+	defaultOutboxTable        = "outbox"
+	defaultOutboxBatchSize    = 100
+	defaultOutboxPollInterval = time.Second
+	defaultOutboxMaxAttempts  = 10
+	defaultOutboxLease        = time.Minute
+)
+
+// Publisher publishes the events of an Outbox. It is implemented by
+// pubsubboot.PubSub.
+type Publisher interface {
+	PublishEvent(ctx context.Context, channel string, eventName string, payload any) error
+}
+
+// Outbox implements the transactional outbox pattern. Events are inserted
+// in the same transaction as the changes they describe and published
+// afterwards by Relay, so no events are lost when the process stops in
+// between.
+//
+// A relay claims a batch of events for the duration of the Lease and commits
+// before publishing them, so no locks are held while publishing. Delivery is
+// at-least-once: an event is published again when marking it as sent fails
+// or its lease expires first, e.g. because the process stops right after
+// publishing. Consumers should be idempotent.
+//
+// Add the migration returned by Outbox.Migration to Postgres.Migrations to
+// create the outbox table.
+type Outbox struct {
+	Postgres  *Postgres
+	Publisher Publisher
+
+	Table        string        // default is "outbox"
+	BatchSize    int           // maximum number of events published per poll, default is 100
+	PollInterval time.Duration // time between polls when there are no events to publish, default is 1 second
+	MaxAttempts  int           // events failing to publish this many times are skipped, default is 10
+	Lease        time.Duration // time a relay has to publish the events it claimed, default is 1 minute
+
+	// Retry determines the backoff before publishing a failed event again.
+	// Its MaxRetries is not used, see MaxAttempts. Default is goboot.RetryPolicy defaults.
+	Retry goboot.RetryPolicy
+}
+
+// outboxEvent is a row of the outbox table.
+type outboxEvent struct {
+	ID        int64  `db:"id"`
+	Channel   string `db:"channel"`
+	EventName string `db:"event_name"`
+	Payload   string `db:"payload"`
+	Attempts  int    `db:"attempts"`
+}
+
+// Migration returns a Go migration that creates the outbox table.
+func (o *Outbox) Migration(version uint) *Migration {
+	return &Migration{
+		Version: version,
+		ID:      "create_" + o.table(),
+		Migrate: func(ctx context.Context, tx *sqlx.Tx) error {
+			table := o.quotedTable()
+			_, err := tx.ExecContext(ctx, `
+				CREATE TABLE `+table+` (
+					id BIGSERIAL PRIMARY KEY,
+					channel TEXT NOT NULL,
+					event_name TEXT NOT NULL,
+					payload JSONB NOT NULL,
+					created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+					attempts INT NOT NULL DEFAULT 0,
+					next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+					locked_until TIMESTAMPTZ,
+					last_error TEXT,
+					sent_at TIMESTAMPTZ
+				);
+				CREATE INDEX `+quoteIdentifier(o.table()+"_unsent_idx")+` ON `+table+` (id) WHERE sent_at IS NULL;`,
+			)
+
+			return err //nolint:wrapcheck // wrapped by runGoMigration
+		},
+		Rollback: func(ctx context.Context, tx *sqlx.Tx) error {
+			_, err := tx.ExecContext(ctx, `DROP TABLE `+o.quotedTable())
+
+			return err //nolint:wrapcheck // wrapped by runGoMigration
+		},
+	}
+}
+
+// Insert adds an event to the outbox within tx. The event is published on
+// the channel after tx has been committed.
+func (o *Outbox) Insert(ctx context.Context, tx *sqlx.Tx, channel string, eventName string, payload any) error {
+	bytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshalling payload of outbox event %q: %w", eventName, err)
+	}
+
+	query := `INSERT INTO ` + o.quotedTable() + ` (channel, event_name, payload, next_attempt_at) VALUES ($1, $2, $3, $4)`
+	if _, err := tx.ExecContext(ctx, query, channel, eventName, string(bytes), o.Postgres.clock.Now()); err != nil {
+		return fmt.Errorf("inserting outbox event %q: %w", eventName, err)
+	}
+
+	return nil
+}
+
+// Relay publishes outbox events until ctx is cancelled. Multiple relays may
+// run at the same time, each event is claimed by one of them.
+func (o *Outbox) Relay(ctx context.Context) {
+	for ctx.Err() == nil {
+		published, err := o.RelayOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			o.Postgres.log.Error().Err(err).Msg("failed to relay outbox events")
+		}
+
+		// continue right away only when a full batch was published, there may be more
+		if err == nil && published == o.batchSize() {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-o.Postgres.clock.After(o.pollInterval()):
+		}
+	}
+}
+
+// RelayOnce publishes a single batch of unsent events that are due and
+// returns the number of events published. Events that fail to publish are
+// retried with backoff until MaxAttempts is reached.
+func (o *Outbox) RelayOnce(ctx context.Context) (int, error) {
+	events, err := o.claim(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var published int
+
+	for _, event := range events {
+		ok, err := o.publish(ctx, event)
+		if err != nil {
+			return published, err
+		}
+
+		if ok {
+			published++
+		}
+	}
+
+	return published, nil
+}
+
+// claim locks a batch of events that are due until their lease expires and
+// commits, so no lock is held while the events are published.
+func (o *Outbox) claim(ctx context.Context) ([]outboxEvent, error) {
+	var events []outboxEvent
+
+	err := o.Postgres.WithTx(ctx, nil, func(tx *sqlx.Tx) error {
+		now := o.Postgres.clock.Now()
+		query := `UPDATE ` + o.quotedTable() + `
+			SET locked_until = $1
+			WHERE id IN (
+				SELECT id FROM ` + o.quotedTable() + `
+				WHERE sent_at IS NULL AND attempts < $2 AND next_attempt_at <= $3
+					AND (locked_until IS NULL OR locked_until <= $3)
+				ORDER BY id
+				LIMIT $4
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, channel, event_name, payload, attempts`
+
+		events = nil
+
+		err := tx.SelectContext(ctx, &events, query, now.Add(o.lease()), o.maxAttempts(), now, o.batchSize())
+		if err != nil {
+			return fmt.Errorf("claiming outbox events: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// RETURNING doesn't keep the order of the subquery
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+
+	return events, nil
+}
+
+// publish publishes event and marks it as sent, or records the failure.
+// Returns whether the event was published.
+func (o *Outbox) publish(ctx context.Context, event outboxEvent) (bool, error) {
+	pubErr := o.Publisher.PublishEvent(ctx, event.Channel, event.EventName, json.RawMessage(event.Payload))
+	if pubErr == nil {
+		query := `UPDATE ` + o.quotedTable() + ` SET sent_at = $2, locked_until = NULL WHERE id = $1`
+		if _, err := o.Postgres.DB.ExecContext(ctx, query, event.ID, o.Postgres.clock.Now()); err != nil {
+			return false, fmt.Errorf("marking outbox event %d as sent: %w", event.ID, err)
+		}
+
+		return true, nil
+	}
+
+	attempts := event.Attempts + 1
+	nextAttemptAt := o.Postgres.clock.Now().Add(o.Retry.Backoff(attempts))
+	log := o.Postgres.log.Warn()
+
+	if attempts >= o.maxAttempts() {
+		log = o.Postgres.log.Error()
+	}
+
+	log.Err(pubErr).
+		Int64("id", event.ID).
+		Str("channel", event.Channel).
+		Int("attempts", attempts).
+		Time("nextAttemptAt", nextAttemptAt).
+		Msgf("failed to publish outbox event %q", event.EventName)
+
+	query := `UPDATE ` + o.quotedTable() + `
+		SET attempts = $2, next_attempt_at = $3, locked_until = NULL, last_error = $4 WHERE id = $1`
+	if _, err := o.Postgres.DB.ExecContext(ctx, query, event.ID, attempts, nextAttemptAt, pubErr.Error()); err != nil {
+		return false, fmt.Errorf("recording outbox event %d failure: %w", event.ID, err)
+	}
+
+	return false, nil
+}
+
+func (o *Outbox) table() string {
+	if o.Table == "" {
+		return defaultOutboxTable
+	}
+
+	return o.Table
+}
+
+func (o *Outbox) quotedTable() string {
+	return quoteIdentifier(o.table())
+}
+
+func (o *Outbox) batchSize() int {
+	if o.BatchSize <= 0 {
+		return defaultOutboxBatchSize
+	}
+
+	return o.BatchSize
+}
+
+func (o *Outbox) lease() time.Duration {
+	if o.Lease <= 0 {
+		return defaultOutboxLease
+	}
+
+	return o.Lease
+}
+
+func (o *Outbox) pollInterval() time.Duration {
+	if o.PollInterval <= 0 {
+		return defaultOutboxPollInterval
+	}
+
+	return o.PollInterval
+}
+
+func (o *Outbox) maxAttempts() int {
+	if o.MaxAttempts <= 0 {
+		return defaultOutboxMaxAttempts
+	}
+
+	return o.MaxAttempts
+}
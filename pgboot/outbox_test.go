@@ -0,0 +1,172 @@
+package pgboot_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/nielskrijger/goboot"
+	"github.com/nielskrijger/goboot/mocks"
+	"github.com/nielskrijger/goboot/pgboot"
+	"github.com/nielskrijger/goboot/pubsubboot"
+	"github.com/nielskrijger/goboot/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ pgboot.Publisher = (*pubsubboot.PubSub)(nil)
+
+const selectOutboxEvent = "SELECT attempts, last_error, sent_at IS NOT NULL AS sent FROM outbox"
+
+type outboxRow struct {
+	Attempts  int     `db:"attempts"`
+	LastError *string `db:"last_error"`
+	Sent      bool    `db:"sent"`
+}
+
+func newTestOutbox(t *testing.T, publisher *mocks.Publisher, clock goboot.Clock) *pgboot.Outbox {
+	t.Helper()
+
+	outbox := &pgboot.Outbox{
+		Publisher:   publisher,
+		MaxAttempts: 2,
+		Retry:       goboot.RetryPolicy{InitialInterval: time.Second, Jitter: -1},
+	}
+	outbox.Postgres = newTestPostgresWithClock(t, clock, "", outbox.Migration(1))
+
+	err := outbox.Postgres.WithTx(context.Background(), nil, func(tx *sqlx.Tx) error {
+		return outbox.Insert(context.Background(), tx, "users", "user.created", map[string]any{"id": 1})
+	})
+	assert.Nil(t, err)
+
+	return outbox
+}
+
+func TestOutbox_RelayPublishesEvents(t *testing.T) {
+	publisher := &mocks.Publisher{}
+	outbox := newTestOutbox(t, publisher, test.NewFakeClock(time.Now()))
+	isPayload := mock.MatchedBy(func(payload json.RawMessage) bool {
+		return assert.JSONEq(t, `{"id":1}`, string(payload))
+	})
+	publisher.On("PublishEvent", mock.Anything, "users", "user.created", isPayload).Return(nil).Once()
+
+	n, err := outbox.RelayOnce(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, n)
+	assertRow(t, outbox.Postgres, outboxRow{Sent: true}, selectOutboxEvent)
+
+	// sent events are not published again
+	n, err = outbox.RelayOnce(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 0, n)
+	publisher.AssertExpectations(t)
+}
+
+func TestOutbox_RelayPublishesClaimedEventsOutsideTransaction(t *testing.T) {
+	clock := test.NewFakeClock(time.Now())
+	publisher := &mocks.Publisher{}
+	outbox := newTestOutbox(t, publisher, clock)
+	outbox.Lease = time.Minute
+	publisher.On("PublishEvent", mock.Anything, "users", "user.created", mock.Anything).
+		Run(func(mock.Arguments) {
+			// the event is not locked while publishing
+			_, err := outbox.Postgres.DB.Exec("SELECT id FROM outbox FOR UPDATE NOWAIT")
+			assert.Nil(t, err)
+
+			// but claimed, so other relays skip it
+			n, err := outbox.RelayOnce(context.Background())
+			assert.Nil(t, err)
+			assert.Equal(t, 0, n)
+		}).
+		Return(errors.New("unavailable")).Once()
+
+	_, err := outbox.RelayOnce(context.Background())
+	assert.Nil(t, err)
+	publisher.AssertExpectations(t)
+}
+
+func TestOutbox_RelayClaimsEventsAgainWhenLeaseExpired(t *testing.T) {
+	clock := test.NewFakeClock(time.Now())
+	publisher := &mocks.Publisher{}
+	outbox := newTestOutbox(t, publisher, clock)
+	publisher.On("PublishEvent", mock.Anything, "users", "user.created", mock.Anything).Return(nil).Once()
+
+	// claimed by a relay that stopped before publishing
+	_, err := outbox.Postgres.DB.Exec("UPDATE outbox SET locked_until = $1", clock.Now().Add(time.Minute))
+	assert.Nil(t, err)
+
+	n, err := outbox.RelayOnce(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 0, n)
+
+	clock.Advance(time.Minute)
+
+	n, err = outbox.RelayOnce(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, n)
+	publisher.AssertExpectations(t)
+}
+
+func TestOutbox_RelayRetriesFailedEventsWithBackoff(t *testing.T) {
+	clock := test.NewFakeClock(time.Now())
+	publisher := &mocks.Publisher{}
+	outbox := newTestOutbox(t, publisher, clock)
+	publisher.On("PublishEvent", mock.Anything, "users", "user.created", mock.Anything).
+		Return(errors.New("unavailable")).Twice()
+
+	n, err := outbox.RelayOnce(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 0, n)
+
+	lastError := "unavailable"
+	assertRow(t, outbox.Postgres, outboxRow{Attempts: 1, LastError: &lastError}, selectOutboxEvent)
+
+	// the event is not published again until the backoff has passed
+	_, err = outbox.RelayOnce(context.Background())
+	assert.Nil(t, err)
+	assertRow(t, outbox.Postgres, outboxRow{Attempts: 1, LastError: &lastError}, selectOutboxEvent)
+
+	clock.Advance(time.Second)
+
+	_, err = outbox.RelayOnce(context.Background())
+	assert.Nil(t, err)
+	assertRow(t, outbox.Postgres, outboxRow{Attempts: 2, LastError: &lastError}, selectOutboxEvent)
+
+	// events are skipped after MaxAttempts
+	clock.Advance(time.Hour)
+
+	_, err = outbox.RelayOnce(context.Background())
+	assert.Nil(t, err)
+	assertRow(t, outbox.Postgres, outboxRow{Attempts: 2, LastError: &lastError}, selectOutboxEvent)
+	publisher.AssertExpectations(t)
+}
+
+func TestOutbox_RelayStopsWhenContextIsDone(t *testing.T) {
+	publisher := &mocks.Publisher{}
+	outbox := newTestOutbox(t, publisher, test.NewFakeClock(time.Now()))
+	publisher.On("PublishEvent", mock.Anything, "users", "user.created", mock.Anything).Return(nil).Once()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+
+	go func() {
+		outbox.Relay(ctx)
+		close(stopped)
+	}()
+
+	// the relay waits for the fake clock after publishing the event, which is never advanced
+	assert.Eventually(t, func() bool {
+		var sent bool
+		assert.Nil(t, outbox.Postgres.DB.Get(&sent, "SELECT sent_at IS NOT NULL FROM outbox"))
+
+		return sent
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+
+	assert.Eventually(t, func() bool { return isClosed(stopped) }, time.Second, 10*time.Millisecond)
+	publisher.AssertExpectations(t)
+}
@@ -0,0 +1,124 @@
+package pgboot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/stdlib"
+	"github.com/jmoiron/sqlx/reflectx"
+)
+
+var (
+	errNotPgxConn       = errors.New("Postgres connection is not a pgx connection")
+	errNotStructSlice   = errors.New("rows must be a slice of structs or struct pointers")
+	errNoStructColumns  = errors.New("struct has no exported fields to copy")
+	errNilStructPointer = errors.New("rows contains a nil struct pointer")
+)
+
+// CopyFrom inserts rows into table using the COPY protocol, which is much
+// faster than inserting rows one by one. The values of each row are in the
+// same order as columns. The table name may be schema-qualified, e.g.
+// "public.users".
+//
+// A single CopyFrom is all-or-nothing: when a row fails no rows are copied.
+// Multiple calls run independently of each other, so an earlier copy stays
+// when a later one fails.
+//
+// Returns the number of rows copied.
+func (s *Postgres) CopyFrom(ctx context.Context, table string, columns []string, rows [][]any) (int64, error) {
+	return s.copyFrom(ctx, table, columns, pgx.CopyFromRows(rows))
+}
+
+// CopyFromStructs is like CopyFrom but takes a slice of structs, or struct
+// pointers, and copies their fields into the columns named by their "db"
+// tags like sqlx does. The fields of embedded structs are copied into their
+// own columns, other struct fields such as a time.Time are copied as is.
+//
+// Returns the number of rows copied.
+func (s *Postgres) CopyFromStructs(ctx context.Context, table string, rows any) (int64, error) {
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice || reflectx.Deref(v.Type().Elem()).Kind() != reflect.Struct {
+		return 0, fmt.Errorf("copying into %q: %w, got %T", table, errNotStructSlice, rows)
+	}
+
+	fields := copyFields(s.DB.Mapper.TypeMap(reflectx.Deref(v.Type().Elem())))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("copying into %q: %w", table, errNoStructColumns)
+	}
+
+	columns := make([]string, len(fields))
+	for i, field := range fields {
+		columns[i] = field.Name
+	}
+
+	src := pgx.CopyFromSlice(v.Len(), func(i int) ([]any, error) {
+		row := reflect.Indirect(v.Index(i))
+		if !row.IsValid() {
+			return nil, fmt.Errorf("%w at index %d", errNilStructPointer, i)
+		}
+
+		values := make([]any, len(fields))
+		for j, field := range fields {
+			values[j] = reflectx.FieldByIndexesReadOnly(row, field.Index).Interface()
+		}
+
+		return values, nil
+	})
+
+	return s.copyFrom(ctx, table, columns, src)
+}
+
+// copyFields returns the fields that map to a column, which are the top-level
+// fields and the fields of embedded structs.
+func copyFields(structMap *reflectx.StructMap) []*reflectx.FieldInfo {
+	var fields []*reflectx.FieldInfo
+
+	for _, field := range structMap.Index {
+		if field.Embedded || strings.Contains(field.Path, ".") || structMap.Paths[field.Path] != field {
+			continue
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+// copyFrom runs COPY on the pgx connection underneath a connection of the pool.
+func (s *Postgres) copyFrom(
+	ctx context.Context,
+	table string,
+	columns []string,
+	src pgx.CopyFromSource,
+) (int64, error) {
+	conn, err := s.DB.Conn(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("reserving Postgres connection for COPY: %w", err)
+	}
+
+	defer conn.Close()
+
+	var copied int64
+
+	err = conn.Raw(func(driverConn any) error {
+		pgxConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errNotPgxConn
+		}
+
+		copied, err = pgxConn.Conn().CopyFrom(ctx, pgx.Identifier(strings.Split(table, ".")), columns, src)
+
+		return err //nolint:wrapcheck // wrapped below
+	})
+	if err != nil {
+		return 0, TranslateError(fmt.Errorf("copying into %q: %w", table, err))
+	}
+
+	s.log.Debug().Int64("rows", copied).Str("table", table).Msg("copied rows into Postgres table")
+
+	return copied, nil
+}
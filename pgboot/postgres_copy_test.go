@@ -0,0 +1,65 @@
+package pgboot_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nielskrijger/goboot"
+	"github.com/nielskrijger/goboot/pgboot"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostgresCopyFrom_Success(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "valid"), "./testdata/migrations")
+
+	copied, err := s.CopyFrom(context.Background(), "test_table", []string{"name"}, [][]any{
+		{"Third record"},
+		{"Fourth record"},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), copied)
+	assert.Equal(t, 4, countRecords(t, s))
+}
+
+func TestPostgresCopyFrom_UniqueViolation(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "valid"), "./testdata/migrations")
+
+	_, err := s.CopyFrom(context.Background(), "test_table", []string{"name"}, [][]any{{"First record"}})
+
+	assert.ErrorIs(t, err, pgboot.ErrUniqueViolation)
+	assert.Equal(t, 2, countRecords(t, s))
+}
+
+func TestPostgresCopyFromStructs_Success(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "valid"), "./testdata/migrations")
+
+	type record struct {
+		Name    string `db:"name"`
+		Ignored string `db:"-"`
+	}
+
+	copied, err := s.CopyFromStructs(context.Background(), "test_table", []*record{
+		{Name: "Third record"},
+		{Name: "Fourth record"},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), copied)
+
+	var names []string
+	assert.Nil(t, s.DB.Select(&names, "SELECT name FROM test_table ORDER BY id"))
+	assert.Equal(t, []string{"First record", "Second record", "Third record", "Fourth record"}, names)
+}
+
+func TestPostgresCopyFromStructs_ErrorNotStructSlice(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "valid"), "./testdata/migrations")
+
+	_, err := s.CopyFromStructs(context.Background(), "test_table", []string{"Third record"})
+
+	assert.EqualError(
+		t,
+		err,
+		`copying into "test_table": rows must be a slice of structs or struct pointers, got []string`,
+	)
+}
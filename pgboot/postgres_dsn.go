@@ -0,0 +1,82 @@
+package pgboot
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const defaultPostgresPort = 5432
+
+var errPasswordWithoutUser = errors.New("config \"postgres.user\" is required when a password is set")
+
+// buildDSN builds a URL-formatted DSN from the discrete connection settings.
+// Relative file paths are resolved from confDir.
+func (c *PostgresConfig) buildDSN(confDir string) (string, error) {
+	password := c.Password
+
+	if c.PasswordFile != "" {
+		b, err := os.ReadFile(resolvePath(confDir, c.PasswordFile))
+		if err != nil {
+			return "", fmt.Errorf("reading Postgres password file: %w", err)
+		}
+
+		password = strings.TrimRight(string(b), "\r\n")
+	}
+
+	if c.User == "" && password != "" {
+		return "", errPasswordWithoutUser
+	}
+
+	port := c.Port
+	if port == 0 {
+		port = defaultPostgresPort
+	}
+
+	q := url.Values{}
+
+	for key, value := range c.Params {
+		q.Set(key, value)
+	}
+
+	if c.SSLMode != "" {
+		q.Set("sslmode", c.SSLMode)
+	}
+
+	for key, path := range map[string]string{
+		"sslrootcert": c.SSLRootCert,
+		"sslcert":     c.SSLCert,
+		"sslkey":      c.SSLKey,
+	} {
+		if path != "" {
+			q.Set(key, resolvePath(confDir, path))
+		}
+	}
+
+	u := url.URL{
+		Scheme:   "postgres",
+		Host:     net.JoinHostPort(c.Host, strconv.Itoa(port)),
+		Path:     "/" + c.Database,
+		RawQuery: q.Encode(),
+	}
+
+	if c.User != "" {
+		u.User = url.UserPassword(c.User, password)
+	}
+
+	return u.String(), nil
+}
+
+// resolvePath returns path relative to confDir unless path is absolute.
+func resolvePath(confDir string, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(confDir, path)
+}
@@ -0,0 +1,188 @@
+package pgboot
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgconn"
+)
+
+const (
+	sqlStateForeignKeyViolation = "23503"
+	sqlStateUniqueViolation     = "23505"
+	sqlStateCheckViolation      = "23514"
+	sqlStateQueryCanceled       = "57014"
+	sqlStateLockNotAvailable    = "55P03"
+	sqlStateIdleInTxTimeout     = "25P03"
+)
+
+// Sentinel errors to check the class of an error returned by TranslateError
+// with errors.Is. Use errors.As with the typed errors for details such as the
+// violated constraint.
+var (
+	ErrNotFound             = errors.New("not found")
+	ErrUniqueViolation      = errors.New("unique violation")
+	ErrForeignKeyViolation  = errors.New("foreign key violation")
+	ErrCheckViolation       = errors.New("check violation")
+	ErrSerializationFailure = errors.New("serialization failure")
+	ErrQueryCanceled        = errors.New("query canceled")
+	ErrTimeout              = errors.New("timeout")
+)
+
+// keyDetailPattern matches the column(s) in the detail of a key violation,
+// e.g. `Key (email)=(jane@example.com) already exists.`.
+var keyDetailPattern = regexp.MustCompile(`^Key \((.+?)\)=`)
+
+// UniqueViolation is returned when an insert or update violates a unique
+// constraint.
+type UniqueViolation struct {
+	Constraint string
+	Column     string // comma-separated when the constraint spans multiple columns
+	Err        error
+}
+
+func (e *UniqueViolation) Error() string        { return e.Err.Error() }
+func (e *UniqueViolation) Unwrap() error        { return e.Err }
+func (e *UniqueViolation) Is(target error) bool { return target == ErrUniqueViolation } //nolint:errorlint
+
+// ForeignKeyViolation is returned when an insert or update references a row
+// that doesn't exist, or a delete removes a row that is still referenced.
+type ForeignKeyViolation struct {
+	Constraint string
+	Table      string
+	Column     string // comma-separated when the constraint spans multiple columns
+	Err        error
+}
+
+func (e *ForeignKeyViolation) Error() string        { return e.Err.Error() }
+func (e *ForeignKeyViolation) Unwrap() error        { return e.Err }
+func (e *ForeignKeyViolation) Is(target error) bool { return target == ErrForeignKeyViolation } //nolint:errorlint
+
+// CheckViolation is returned when an insert or update violates a check
+// constraint.
+type CheckViolation struct {
+	Constraint string
+	Table      string
+	Err        error
+}
+
+func (e *CheckViolation) Error() string        { return e.Err.Error() }
+func (e *CheckViolation) Unwrap() error        { return e.Err }
+func (e *CheckViolation) Is(target error) bool { return target == ErrCheckViolation } //nolint:errorlint
+
+// SerializationFailure is returned when a transaction could not be serialized
+// or was aborted to resolve a deadlock. The transaction may succeed when
+// retried, see WithTx.
+type SerializationFailure struct {
+	Err error
+}
+
+func (e *SerializationFailure) Error() string        { return e.Err.Error() }
+func (e *SerializationFailure) Unwrap() error        { return e.Err }
+func (e *SerializationFailure) Is(target error) bool { return target == ErrSerializationFailure } //nolint:errorlint
+
+// QueryCanceled is returned when a query was canceled, e.g. because its
+// context was canceled. A statement timeout returns a TimeoutError instead.
+type QueryCanceled struct {
+	Err error
+}
+
+func (e *QueryCanceled) Error() string        { return e.Err.Error() }
+func (e *QueryCanceled) Unwrap() error        { return e.Err }
+func (e *QueryCanceled) Is(target error) bool { return target == ErrQueryCanceled } //nolint:errorlint
+
+// Timeouts that cause a TimeoutError.
+const (
+	StatementTimeout         = "statement_timeout"
+	LockTimeout              = "lock_timeout"
+	IdleInTransactionTimeout = "idle_in_transaction_session_timeout"
+)
+
+// TimeoutError is returned when a query is canceled by the statement or lock
+// timeout, or the session is terminated by the idle in transaction timeout.
+type TimeoutError struct {
+	Timeout string // one of StatementTimeout, LockTimeout or IdleInTransactionTimeout
+	Err     error
+}
+
+func (e *TimeoutError) Error() string        { return e.Err.Error() }
+func (e *TimeoutError) Unwrap() error        { return e.Err }
+func (e *TimeoutError) Is(target error) bool { return target == ErrTimeout } //nolint:errorlint
+
+// notFound wraps sql.ErrNoRows so it matches both sql.ErrNoRows and ErrNotFound.
+type notFound struct {
+	err error
+}
+
+func (e *notFound) Error() string        { return e.err.Error() }
+func (e *notFound) Unwrap() error        { return e.err }
+func (e *notFound) Is(target error) bool { return target == ErrNotFound } //nolint:errorlint
+
+// TranslateError classifies err, e.g. to map a unique violation to a 409
+// Conflict without matching SQLSTATE codes:
+//
+//	var unique *pgboot.UniqueViolation
+//	if errors.As(pgboot.TranslateError(err), &unique) {
+//		return fmt.Errorf("%s is already taken", unique.Column)
+//	}
+//
+// The translated error keeps the message of err and still wraps it. Errors
+// that aren't classified are returned as is.
+func TranslateError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return &notFound{err: err}
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch pgErr.Code {
+	case sqlStateUniqueViolation:
+		return &UniqueViolation{Constraint: pgErr.ConstraintName, Column: violatedColumn(pgErr), Err: err}
+	case sqlStateForeignKeyViolation:
+		return &ForeignKeyViolation{
+			Constraint: pgErr.ConstraintName,
+			Table:      pgErr.TableName,
+			Column:     violatedColumn(pgErr),
+			Err:        err,
+		}
+	case sqlStateCheckViolation:
+		return &CheckViolation{Constraint: pgErr.ConstraintName, Table: pgErr.TableName, Err: err}
+	case sqlStateSerializationFailure, sqlStateDeadlockDetected:
+		return &SerializationFailure{Err: err}
+	case sqlStateQueryCanceled:
+		if strings.Contains(pgErr.Message, "statement timeout") {
+			return &TimeoutError{Timeout: StatementTimeout, Err: err}
+		}
+
+		return &QueryCanceled{Err: err}
+	case sqlStateLockNotAvailable:
+		if strings.Contains(pgErr.Message, "lock timeout") {
+			return &TimeoutError{Timeout: LockTimeout, Err: err}
+		}
+
+		return err
+	case sqlStateIdleInTxTimeout:
+		return &TimeoutError{Timeout: IdleInTransactionTimeout, Err: err}
+	default:
+		return err
+	}
+}
+
+// violatedColumn returns the column of a key violation. Postgres doesn't set
+// the column field for key violations so it is parsed from the detail message.
+func violatedColumn(pgErr *pgconn.PgError) string {
+	if pgErr.ColumnName != "" {
+		return pgErr.ColumnName
+	}
+
+	if match := keyDetailPattern.FindStringSubmatch(pgErr.Detail); match != nil {
+		return match[1]
+	}
+
+	return ""
+}
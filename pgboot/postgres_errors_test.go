@@ -0,0 +1,118 @@
+package pgboot_test
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	"github.com/nielskrijger/goboot/pgboot"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslateError_NotFound(t *testing.T) {
+	err := pgboot.TranslateError(fmt.Errorf("fetching user: %w", sql.ErrNoRows))
+
+	assert.ErrorIs(t, err, pgboot.ErrNotFound)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.EqualError(t, err, "fetching user: sql: no rows in result set")
+}
+
+func TestTranslateError_UniqueViolation(t *testing.T) {
+	err := pgboot.TranslateError(fmt.Errorf("inserting user: %w", &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           "23505",
+		Message:        `duplicate key value violates unique constraint "users_email_key"`,
+		Detail:         "Key (email)=(jane@example.com) already exists.",
+		ConstraintName: "users_email_key",
+	}))
+
+	var unique *pgboot.UniqueViolation
+	if assert.ErrorAs(t, err, &unique) {
+		assert.Equal(t, "users_email_key", unique.Constraint)
+		assert.Equal(t, "email", unique.Column)
+	}
+
+	assert.ErrorIs(t, err, pgboot.ErrUniqueViolation)
+	assert.NotErrorIs(t, err, pgboot.ErrForeignKeyViolation)
+	assert.EqualError(
+		t,
+		err,
+		`inserting user: ERROR: duplicate key value violates unique constraint "users_email_key" (SQLSTATE 23505)`,
+	)
+}
+
+func TestTranslateError_ForeignKeyViolation(t *testing.T) {
+	err := pgboot.TranslateError(&pgconn.PgError{
+		Code:           "23503",
+		Detail:         `Key (user_id, tenant_id)=(1, 2) is not present in table "users".`,
+		TableName:      "orders",
+		ConstraintName: "orders_user_fkey",
+	})
+
+	var fk *pgboot.ForeignKeyViolation
+	if assert.ErrorAs(t, err, &fk) {
+		assert.Equal(t, "orders_user_fkey", fk.Constraint)
+		assert.Equal(t, "orders", fk.Table)
+		assert.Equal(t, "user_id, tenant_id", fk.Column)
+	}
+
+	assert.ErrorIs(t, err, pgboot.ErrForeignKeyViolation)
+}
+
+func TestTranslateError_Classes(t *testing.T) {
+	tests := map[string]error{
+		"23514": pgboot.ErrCheckViolation,
+		"40001": pgboot.ErrSerializationFailure,
+		"40P01": pgboot.ErrSerializationFailure,
+		"57014": pgboot.ErrQueryCanceled,
+	}
+
+	for code, want := range tests {
+		err := pgboot.TranslateError(&pgconn.PgError{Code: code})
+
+		assert.ErrorIs(t, err, want, code)
+
+		var pgErr *pgconn.PgError
+		assert.ErrorAs(t, err, &pgErr, code)
+	}
+}
+
+func TestTranslateError_Unclassified(t *testing.T) {
+	pgErr := &pgconn.PgError{Code: "42P01"}
+	assert.Same(t, pgErr, pgboot.TranslateError(pgErr))
+
+	err := errors.New("some error")
+	assert.Same(t, err, pgboot.TranslateError(err))
+	assert.Nil(t, pgboot.TranslateError(nil))
+}
+
+func TestTranslateError_Timeouts(t *testing.T) {
+	tests := map[string]*pgconn.PgError{
+		pgboot.StatementTimeout: {Code: "57014", Message: "canceling statement due to statement timeout"},
+		pgboot.LockTimeout:      {Code: "55P03", Message: "canceling statement due to lock timeout"},
+		pgboot.IdleInTransactionTimeout: {
+			Code:    "25P03",
+			Message: "terminating connection due to idle-in-transaction timeout",
+		},
+	}
+
+	for timeout, pgErr := range tests {
+		err := pgboot.TranslateError(pgErr)
+
+		var timeoutErr *pgboot.TimeoutError
+		if assert.ErrorAs(t, err, &timeoutErr, timeout) {
+			assert.Equal(t, timeout, timeoutErr.Timeout)
+		}
+
+		assert.ErrorIs(t, err, pgboot.ErrTimeout, timeout)
+	}
+
+	// canceled by the user and NOWAIT locks are no timeouts
+	canceled := &pgconn.PgError{Code: "57014", Message: "canceling statement due to user request"}
+	assert.ErrorIs(t, pgboot.TranslateError(canceled), pgboot.ErrQueryCanceled)
+
+	noWait := &pgconn.PgError{Code: "55P03", Message: `could not obtain lock on row in relation "test_table"`}
+	assert.NotErrorIs(t, pgboot.TranslateError(noWait), pgboot.ErrTimeout)
+}
@@ -0,0 +1,155 @@
+package pgboot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// Listen subscribes to notifications on channel and calls handler with the
+// payload of every notification, one at a time.
+//
+// Each Listen call uses a dedicated connection outside of the connection
+// pool. After connection failures Listen reconnects and issues LISTEN again,
+// notifications sent while disconnected are lost. Listening stops when ctx
+// is cancelled or the service is closed.
+func (s *Postgres) Listen(ctx context.Context, channel string, handler func(payload string)) error {
+	ctx, cancel := context.WithCancel(ctx)
+
+	conn, err := s.listen(ctx, channel)
+	if err != nil {
+		cancel()
+
+		return err
+	}
+
+	s.listenersMu.Lock()
+	if s.stopListeners == nil {
+		s.stopListeners = make(map[uint64]context.CancelFunc)
+	}
+
+	id := s.nextListener
+	s.nextListener++
+	s.stopListeners[id] = cancel
+	s.listeners.Add(1)
+	s.listenersMu.Unlock()
+
+	go func() {
+		defer s.listeners.Done()
+		defer s.removeListener(id)
+		defer cancel()
+
+		s.receiveNotifications(ctx, conn, channel, handler)
+	}()
+
+	return nil
+}
+
+// Notify sends a notification with payload on channel.
+func (s *Postgres) Notify(ctx context.Context, channel string, payload string) error {
+	if _, err := s.DB.ExecContext(ctx, "SELECT pg_notify($1, $2)", channel, payload); err != nil {
+		return fmt.Errorf("notifying Postgres channel %q: %w", channel, err)
+	}
+
+	return nil
+}
+
+// listen opens a dedicated connection and issues LISTEN on channel.
+func (s *Postgres) listen(ctx context.Context, channel string) (*pgx.Conn, error) {
+	connConfig, err := s.connConfig(s.config.DSN)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := pgx.ConnectConfig(ctx, connConfig)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to Postgres to listen on channel %q: %w", channel, err)
+	}
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		_ = conn.Close(context.Background())
+
+		return nil, fmt.Errorf("listening on Postgres channel %q: %w", channel, err)
+	}
+
+	return conn, nil
+}
+
+// receiveNotifications passes notifications to handler and reconnects upon
+// failure until ctx is cancelled.
+func (s *Postgres) receiveNotifications(
+	ctx context.Context,
+	conn *pgx.Conn,
+	channel string,
+	handler func(payload string),
+) {
+	defer func() {
+		if conn != nil {
+			_ = conn.Close(context.Background())
+		}
+	}()
+
+	for {
+		if conn == nil {
+			err := s.config.Retry.Retry(ctx, s.clock, func(ctx context.Context) (err error) {
+				conn, err = s.listen(ctx, channel)
+
+				return err
+			}, func(err error, wait time.Duration) {
+				s.log.Warn().
+					Err(err).
+					Str("channel", channel).
+					Msgf("failed to reconnect Postgres listener, retrying in %s", wait)
+			})
+
+			switch {
+			case ctx.Err() != nil:
+				return
+			case err != nil:
+				s.log.Error().Err(err).Str("channel", channel).Msg("failed to reconnect Postgres listener, starting over")
+
+				continue
+			}
+
+			s.log.Info().Str("channel", channel).Msg("reconnected Postgres listener")
+		}
+
+		notification, err := conn.WaitForNotification(ctx)
+
+		switch {
+		case err == nil:
+			handler(notification.Payload)
+		case ctx.Err() != nil || errors.Is(err, context.Canceled):
+			return
+		default:
+			s.log.Warn().Err(err).Str("channel", channel).Msg("Postgres listener connection lost, reconnecting")
+
+			_ = conn.Close(context.Background())
+			conn = nil
+		}
+	}
+}
+
+// removeListener forgets the listener with id after it has stopped.
+func (s *Postgres) removeListener(id uint64) {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+
+	delete(s.stopListeners, id)
+}
+
+// closeListeners stops all listeners and waits until their connections are closed.
+func (s *Postgres) closeListeners() {
+	s.listenersMu.Lock()
+	for _, cancel := range s.stopListeners {
+		cancel()
+	}
+
+	s.stopListeners = nil
+	s.listenersMu.Unlock()
+
+	s.listeners.Wait()
+}
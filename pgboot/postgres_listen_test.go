@@ -0,0 +1,82 @@
+package pgboot_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nielskrijger/goboot"
+	"github.com/nielskrijger/goboot/pgboot"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostgresListen_ReceivesNotifications(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "valid"), "")
+	payloads := make(chan string, 2)
+
+	assert.Nil(t, s.Listen(context.Background(), "cache-invalidation", func(payload string) {
+		payloads <- payload
+	}))
+
+	assert.Nil(t, s.Notify(context.Background(), "cache-invalidation", "user:1"))
+	assert.Nil(t, s.Notify(context.Background(), "cache-invalidation", "user:2"))
+
+	for _, expected := range []string{"user:1", "user:2"} {
+		select {
+		case payload := <-payloads:
+			assert.Equal(t, expected, payload)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected notification %q", expected)
+		}
+	}
+}
+
+func TestPostgresListen_StopsWhenContextCancelled(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "valid"), "")
+	payloads := make(chan string, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	assert.Nil(t, s.Listen(ctx, "cache-invalidation", func(payload string) {
+		payloads <- payload
+	}))
+
+	cancel()
+	time.Sleep(100 * time.Millisecond)
+
+	assert.Nil(t, s.Notify(context.Background(), "cache-invalidation", "user:1"))
+
+	select {
+	case payload := <-payloads:
+		t.Errorf("unexpected notification %q", payload)
+	case <-time.After(500 * time.Millisecond):
+	}
+}
+
+func TestPostgresListen_ReconnectsAfterConnectionLoss(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "valid"), "")
+	payloads := make(chan string, 10)
+
+	assert.Nil(t, s.Listen(context.Background(), "listen-reconnect", func(payload string) {
+		payloads <- payload
+	}))
+
+	var pids []int
+	assert.Nil(t, s.DB.Select(&pids, `SELECT pid FROM pg_stat_activity WHERE query = 'LISTEN "listen-reconnect"'`))
+
+	if assert.Len(t, pids, 1) {
+		_, err := s.DB.Exec("SELECT pg_terminate_backend($1)", pids[0])
+		assert.Nil(t, err)
+	}
+
+	// notifications sent while reconnecting are lost, keep notifying until one arrives
+	assert.Eventually(t, func() bool {
+		assert.Nil(t, s.Notify(context.Background(), "listen-reconnect", "user:1"))
+
+		select {
+		case payload := <-payloads:
+			return payload == "user:1"
+		case <-time.After(100 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -1,17 +1,28 @@
 package pgboot
 
 import (
-	"database/sql"
+	"context"
 	"errors"
 	"fmt"
+	"io/fs"
+	"os"
 	"path/filepath"
 
 	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/pgx"
-	_ "github.com/golang-migrate/migrate/v4/source/file" // Load file-loader for migration files.
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/nielskrijger/goboot"
 	"github.com/rs/zerolog"
 )
 
+var (
+	errMissingMigrations = errors.New(
+		"no Postgres migrations configured; set MigrationsDir, MigrationsFS or Migrations",
+	)
+	errInvalidSteps = errors.New("number of migrations to roll back must be positive")
+)
+
 type PostgresMigratePrinter interface {
 	Printf(format string, v ...any)
 }
@@ -31,60 +42,420 @@ func (log *logger) Verbose() bool {
 	return true
 }
 
-// Migrate runs Postgres migration files from specified migrations directory.
-//
-// Panics if anything went wrong.
-func (s *Postgres) Migrate(dsn string, migrations string) error {
-	log := logger{logger: s.log}
+// DirtyError is returned when a previous migration failed halfway and left
+// the database in a dirty state. No migrations can run until the dirty state
+// has been resolved using Postgres.Force.
+type DirtyError struct {
+	// Version is the migration version that failed.
+	Version uint
+
+	// PreviousVersion is the version before the failed migration, -1 if the
+	// failed migration was the first one.
+	PreviousVersion int
+}
+
+func (e *DirtyError) Error() string {
+	return fmt.Sprintf(
+		"Postgres database is dirty at migration version %d; a previous migration failed halfway. "+
+			"Fix the database manually, then call Postgres.Force(%d) if version %d was applied completely "+
+			"or Postgres.Force(%d) if its changes were reverted and it should run again",
+		e.Version, e.Version, e.Version, e.PreviousVersion,
+	)
+}
 
+// MigrationStatus describes a single migration in the migrations source.
+type MigrationStatus struct {
+	Version    uint
+	Identifier string // file name without version and extension, e.g. "create_table"
+	Applied    bool
+	Dirty      bool // true if the migration failed halfway
+}
+
+// Migrate runs Postgres migration files from specified migrations directory.
+func (s *Postgres) Migrate(ctx context.Context, migrations string) error {
 	dir, err := filepath.Abs(migrations)
 	if err != nil {
 		return fmt.Errorf("reading migrations path: %w", err)
 	}
 
-	log.Printf("running Postgres migrations from %s", dir)
+	s.log.Info().Msgf("running Postgres migrations from %s", dir)
 
-	// connect to postgres
-	db, err := sql.Open("pgx", dsn)
+	src, err := openDirSource(dir)
 	if err != nil {
-		return fmt.Errorf("connecting to postgres: %w", err)
+		return err
 	}
 
-	defer func() {
-		err := db.Close()
+	return s.withMigrate(ctx, "file", src, s.migrateUp)
+}
+
+// MigrateFS runs Postgres migration files from a file system such as embed.FS.
+// The migration files must be in the root of fsys, use fs.Sub to select a
+// subdirectory.
+func (s *Postgres) MigrateFS(ctx context.Context, fsys fs.FS) error {
+	src, err := openFSSource(fsys)
+	if err != nil {
+		return err
+	}
+
+	s.log.Info().Msg("running Postgres migrations from embedded file system")
+
+	return s.withMigrate(ctx, "iofs", src, s.migrateUp)
+}
+
+// MigrateDown rolls back specified number of migrations.
+func (s *Postgres) MigrateDown(ctx context.Context, steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("rolling back %d Postgres migrations: %w", steps, errInvalidSteps)
+	}
+
+	return s.withConfiguredMigrate(ctx, func(m *migrate.Migrate, _ source.Driver) error {
+		if err := m.Steps(-steps); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+			return fmt.Errorf("rolling back %d Postgres migrations: %w", steps, err)
+		}
+
+		return nil
+	})
+}
+
+// MigrateTo migrates up or down to specified version.
+func (s *Postgres) MigrateTo(ctx context.Context, version uint) error {
+	return s.withConfiguredMigrate(ctx, func(m *migrate.Migrate, _ source.Driver) error {
+		if err := m.Migrate(version); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+			return fmt.Errorf("migrating Postgres to version %d: %w", version, err)
+		}
+
+		return nil
+	})
+}
+
+// Version returns the current migration version and whether the database is
+// dirty. Returns version 0 if no migrations have been applied.
+func (s *Postgres) Version(ctx context.Context) (version uint, dirty bool, err error) {
+	err = s.withConfiguredMigrate(ctx, func(m *migrate.Migrate, _ source.Driver) error {
+		version, dirty, err = m.Version()
+		if errors.Is(err, migrate.ErrNilVersion) {
+			return nil
+		}
+
 		if err != nil {
-			panic(err)
+			return fmt.Errorf("reading Postgres migration version: %w", err)
 		}
-	}()
 
-	p := &pgx.Postgres{}
+		return nil
+	})
+
+	return version, dirty, err
+}
+
+// Force sets the migration version and clears the dirty flag without running
+// any migrations. Use version -1 to mark no migrations as applied.
+func (s *Postgres) Force(ctx context.Context, version int) error {
+	return s.withConfiguredMigrate(ctx, func(m *migrate.Migrate, _ source.Driver) error {
+		if err := m.Force(version); err != nil {
+			return fmt.Errorf("forcing Postgres migration version %d: %w", version, err)
+		}
+
+		s.log.Info().Msgf("forced Postgres migration version to %d", version)
+
+		return nil
+	})
+}
+
+// Status lists all migrations of the migrations source in order and whether
+// they have been applied.
+func (s *Postgres) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var result []MigrationStatus
 
-	driver, err := p.Open(dsn)
+	err := s.withConfiguredMigrate(ctx, func(m *migrate.Migrate, src source.Driver) (err error) {
+		result, err = migrationStatus(m, src)
+
+		return err
+	})
+
+	return result, err
+}
+
+// migrationStatus returns the status of all migrations in src.
+func migrationStatus(m *migrate.Migrate, src source.Driver) ([]MigrationStatus, error) {
+	current, dirty, err := m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return nil, fmt.Errorf("reading Postgres migration version: %w", err)
+	}
+
+	applied := !errors.Is(err, migrate.ErrNilVersion)
+
+	versions, err := sourceVersions(src)
 	if err != nil {
-		return fmt.Errorf("open Postgres connection for golang-migrate: %w", err)
+		return nil, err
 	}
 
-	// setup migrations connection
-	m, err := migrate.NewWithDatabaseInstance(
-		"file://"+dir,
-		"postgres",
-		driver,
+	result := make([]MigrationStatus, 0, len(versions))
+
+	for _, v := range versions {
+		identifier, err := sourceIdentifier(src, v)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, MigrationStatus{
+			Version:    v,
+			Identifier: identifier,
+			Applied:    applied && v <= current,
+			Dirty:      dirty && v == current,
+		})
+	}
+
+	return result, nil
+}
+
+// withConfiguredMigrate runs f using the migrations configured in MigrationsFS
+// or MigrationsDir, and Migrations.
+func (s *Postgres) withConfiguredMigrate(
+	ctx context.Context,
+	f func(m *migrate.Migrate, src source.Driver) error,
+) error {
+	var (
+		src        source.Driver
+		sourceName string
+		err        error
 	)
+
+	switch {
+	case s.MigrationsFS != nil:
+		sourceName = "iofs"
+		src, err = openFSSource(s.MigrationsFS)
+	case s.MigrationsDir != "":
+		sourceName = "file"
+		src, err = openDirSource(s.MigrationsDir)
+	case len(s.Migrations) > 0:
+		sourceName = "go"
+	default:
+		return errMissingMigrations
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return s.withMigrate(ctx, sourceName, src, f)
+}
+
+func openDirSource(migrations string) (source.Driver, error) {
+	dir, err := filepath.Abs(migrations)
+	if err != nil {
+		return nil, fmt.Errorf("reading migrations path: %w", err)
+	}
+
+	src, err := (&file.File{}).Open("file://" + dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading migrations directory %s: %w", dir, err)
+	}
+
+	return src, nil
+}
+
+func openFSSource(fsys fs.FS) (source.Driver, error) {
+	src, err := iofs.New(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("reading migrations file system: %w", err)
+	}
+
+	return src, nil
+}
+
+// withMigrate creates a golang-migrate instance for specified source on a
+// connection of the service's connection pool and runs f. Go migrations are
+// added to the SQL source, src is nil when there are only Go migrations.
+//
+// Cancelling ctx stops running migrations after the current one completes
+// and aborts any running query. The connection is returned to the pool and
+// the source is closed when done.
+func (s *Postgres) withMigrate(
+	ctx context.Context,
+	sourceName string,
+	sqlSrc source.Driver,
+	f func(m *migrate.Migrate, src source.Driver) error,
+) (err error) {
+	src, err := newGoMigrationsSource(sqlSrc, s.Migrations)
+	if err != nil {
+		if sqlSrc != nil {
+			_ = sqlSrc.Close()
+		}
+
+		return err
+	}
+
+	driver, err := newMigrateDriver(ctx, s.DB, src.migrations)
 	if err != nil {
+		_ = src.Close()
+
+		return err
+	}
+
+	m, err := migrate.NewWithInstance(sourceName, src, "postgres", driver)
+	if err != nil {
+		_ = src.Close()
+		_ = driver.Close()
+
 		return fmt.Errorf("connecting to Postgres for migrations: %w", err)
 	}
 
-	m.Log = &log
+	m.Log = &logger{logger: s.log}
+
+	defer func() {
+		srcErr, dbErr := m.Close()
+		if err == nil && srcErr != nil {
+			err = srcErr
+		}
+
+		if err == nil && dbErr != nil {
+			err = dbErr
+		}
+	}()
+
+	stop := make(chan struct{})
+	defer close(stop)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			m.GracefulStop <- true
+		case <-stop:
+		}
+	}()
+
+	return f(m, src)
+}
+
+func (s *Postgres) migrateUp(m *migrate.Migrate, src source.Driver) error {
+	var errDirty migrate.ErrDirty
+
+	err := m.Up()
+
+	switch {
+	case err == nil:
+		m.Log.Printf("completed Postgres migrations")
+	case errors.Is(err, migrate.ErrNoChange):
+		m.Log.Printf("Postgres database is up-to-date")
+	case errors.As(err, &errDirty):
+		dirtyErr := newDirtyError(src, uint(errDirty.Version))
+		s.log.Error().Uint("version", dirtyErr.Version).Msg(dirtyErr.Error())
+
+		return dirtyErr
+	default:
+		if version, dirty, vErr := m.Version(); vErr == nil && dirty {
+			s.log.Error().Uint("version", version).Msg(newDirtyError(src, version).Error())
+		}
+
+		return fmt.Errorf("running Postgres migrations: %w", err)
+	}
+
+	return nil
+}
+
+func newDirtyError(src source.Driver, version uint) *DirtyError {
+	previous := -1
+	if v, err := src.Prev(version); err == nil {
+		previous = int(v)
+	}
+
+	return &DirtyError{Version: version, PreviousVersion: previous}
+}
+
+// sourceVersions returns all migration versions of src in ascending order.
+func sourceVersions(src source.Driver) ([]uint, error) {
+	v, err := src.First()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("reading first migration: %w", err)
+	}
+
+	versions := []uint{v}
+
+	for {
+		next, err := src.Next(v)
+		if errors.Is(err, os.ErrNotExist) {
+			return versions, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("reading next migration after version %d: %w", v, err)
+		}
+
+		versions = append(versions, next)
+		v = next
+	}
+}
+
+// sourceIdentifier returns the migration name of specified version.
+func sourceIdentifier(src source.Driver, version uint) (string, error) {
+	r, identifier, err := src.ReadUp(version)
+	if errors.Is(err, os.ErrNotExist) {
+		r, identifier, err = src.ReadDown(version)
+	}
 
-	err = m.Up()
 	if err != nil {
-		if errors.Is(err, migrate.ErrNoChange) {
-			log.Printf("Postgres database is up-to-date")
-		} else {
-			return fmt.Errorf("running Postgres migrations: %w", err)
+		return "", fmt.Errorf("reading migration %d: %w", version, err)
+	}
+
+	if err := r.Close(); err != nil {
+		return "", fmt.Errorf("closing migration %d: %w", version, err)
+	}
+
+	return identifier, nil
+}
+
+// checkMigrations reports pending migrations without applying them. In
+// verify mode an error is returned when migrations are pending or the
+// database is dirty, in dry run mode they are only logged.
+func (s *Postgres) checkMigrations(ctx context.Context, mode goboot.MigrationsMode) error {
+	return s.withConfiguredMigrate(ctx, func(m *migrate.Migrate, src source.Driver) error {
+		status, err := migrationStatus(m, src)
+		if err != nil {
+			return err
 		}
-	} else {
-		log.Printf("completed Postgres migrations")
+
+		return s.reportMigrations(src, status, mode)
+	})
+}
+
+// reportMigrations returns an error or logs the pending and dirty migrations
+// in status depending on mode.
+func (s *Postgres) reportMigrations(src source.Driver, status []MigrationStatus, mode goboot.MigrationsMode) error {
+	var pending []string
+
+	for _, migration := range status {
+		if migration.Dirty {
+			dirtyErr := newDirtyError(src, migration.Version)
+
+			if mode == goboot.MigrationsVerify {
+				return dirtyErr
+			}
+
+			s.log.Error().Uint("version", migration.Version).Msg(dirtyErr.Error())
+		}
+
+		if !migration.Applied {
+			pending = append(pending, fmt.Sprintf("%d_%s", migration.Version, migration.Identifier))
+
+			if mode == goboot.MigrationsDryRun {
+				s.log.Info().
+					Uint("version", migration.Version).
+					Msgf("pending Postgres migration %d %q", migration.Version, migration.Identifier)
+			}
+		}
+	}
+
+	switch {
+	case len(pending) == 0:
+		s.log.Info().Msg("Postgres database is up-to-date")
+	case mode == goboot.MigrationsVerify:
+		return goboot.PendingMigrationsError(pending)
+	default:
+		s.log.Info().Msgf("dry run; %d pending Postgres migrations were not applied", len(pending))
 	}
 
 	return nil
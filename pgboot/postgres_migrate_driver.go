@@ -0,0 +1,271 @@
+package pgboot
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/jackc/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	migrationsTable        = "schema_migrations"
+	sqlStateUndefinedTable = "42P01"
+)
+
+var errDriverOpenNotSupported = errors.New("opening a migrations driver by url is not supported")
+
+// migrateDriver implements the golang-migrate database.Driver using a single
+// connection of the Postgres service connection pool.
+//
+// Unlike the golang-migrate pgx driver closing the migrateDriver only returns
+// the connection to the pool rather than closing the pool. Go migrations run
+// in a transaction on the same connection, within the session holding the
+// migrations lock. The statement and lock timeouts are disabled on the
+// connection while it is reserved.
+type migrateDriver struct {
+	ctx        context.Context //nolint:containedctx // golang-migrate drivers do not accept a context
+	conn       *sqlx.Conn
+	migrations map[uint]*Migration
+	schema     string
+	lockID     string
+	isLocked   bool
+}
+
+// newMigrateDriver reserves a connection from db for migrations in the
+// current schema.
+func newMigrateDriver(ctx context.Context, db *sqlx.DB, migrations map[uint]*Migration) (*migrateDriver, error) {
+	conn, err := db.Connx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reserving Postgres connection for migrations: %w", err)
+	}
+
+	d := &migrateDriver{ctx: ctx, conn: conn, migrations: migrations}
+
+	if err := d.init(); err != nil {
+		_ = d.Close()
+
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (d *migrateDriver) init() error {
+	var dbName string
+
+	query := `SELECT current_database(), current_schema()`
+	if err := d.conn.QueryRowContext(d.ctx, query).Scan(&dbName, &d.schema); err != nil {
+		return &database.Error{OrigErr: err, Query: []byte(query)}
+	}
+
+	lockID, err := database.GenerateAdvisoryLockId(dbName, d.schema, migrationsTable)
+	if err != nil {
+		return fmt.Errorf("generating migrations lock id: %w", err)
+	}
+
+	d.lockID = lockID
+
+	for name, value := range noTimeouts.runtimeParams() {
+		if _, err := d.conn.ExecContext(d.ctx, `SELECT set_config($1, $2, false)`, name, value); err != nil {
+			return fmt.Errorf("disabling %s for migrations: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func (d *migrateDriver) table() string {
+	return quoteIdentifier(d.schema) + "." + quoteIdentifier(migrationsTable)
+}
+
+// Open is not supported, migrateDriver is always created from the service's
+// connection pool.
+func (d *migrateDriver) Open(string) (database.Driver, error) {
+	return nil, errDriverOpenNotSupported
+}
+
+// Close resets the timeouts and returns the reserved connection to the pool.
+func (d *migrateDriver) Close() error {
+	for name := range noTimeouts.runtimeParams() {
+		if _, err := d.conn.ExecContext(d.ctx, `RESET `+name); err != nil {
+			// discard the connection rather than returning it to the pool without timeouts
+			_ = d.conn.Raw(func(any) error { return driver.ErrBadConn })
+
+			return fmt.Errorf("resetting %s after migrations: %w", name, err)
+		}
+	}
+
+	if err := d.conn.Close(); err != nil {
+		return fmt.Errorf("releasing Postgres migrations connection: %w", err)
+	}
+
+	return nil
+}
+
+func (d *migrateDriver) Lock() error {
+	if d.isLocked {
+		return database.ErrLocked
+	}
+
+	// This will wait until the lock can be acquired or the context is cancelled.
+	query := `SELECT pg_advisory_lock($1)`
+	if _, err := d.conn.ExecContext(d.ctx, query, d.lockID); err != nil {
+		return &database.Error{OrigErr: err, Err: "try lock failed", Query: []byte(query)}
+	}
+
+	d.isLocked = true
+
+	return nil
+}
+
+func (d *migrateDriver) Unlock() error {
+	if !d.isLocked {
+		return database.ErrNotLocked
+	}
+
+	query := `SELECT pg_advisory_unlock($1)`
+	if _, err := d.conn.ExecContext(d.ctx, query, d.lockID); err != nil {
+		return &database.Error{OrigErr: err, Query: []byte(query)}
+	}
+
+	d.isLocked = false
+
+	return nil
+}
+
+func (d *migrateDriver) Run(migration io.Reader) error {
+	b, err := io.ReadAll(migration)
+	if err != nil {
+		return fmt.Errorf("reading migration: %w", err)
+	}
+
+	query := string(b)
+	if strings.TrimSpace(query) == "" {
+		return nil
+	}
+
+	if strings.HasPrefix(query, goMigrationPrefix) {
+		return d.runGoMigration(query)
+	}
+
+	if _, err := d.conn.ExecContext(d.ctx, query); err != nil {
+		return database.Error{OrigErr: err, Err: "migration failed", Query: b}
+	}
+
+	return nil
+}
+
+func (d *migrateDriver) SetVersion(version int, dirty bool) error {
+	tx, err := d.conn.BeginTx(d.ctx, nil)
+	if err != nil {
+		return &database.Error{OrigErr: err, Err: "transaction start failed"}
+	}
+
+	// The migrations table is created on first write so reading the version
+	// works with read-only database users, e.g. in the verify migrations mode.
+	query := `CREATE TABLE IF NOT EXISTS ` + d.table() + ` (version bigint not null primary key, dirty boolean not null)`
+	if _, err := tx.ExecContext(d.ctx, query); err != nil {
+		_ = tx.Rollback()
+
+		return &database.Error{OrigErr: err, Query: []byte(query)}
+	}
+
+	query = `TRUNCATE ` + d.table()
+	if _, err := tx.ExecContext(d.ctx, query); err != nil {
+		_ = tx.Rollback()
+
+		return &database.Error{OrigErr: err, Query: []byte(query)}
+	}
+
+	// Also write the nil version when dirty, see https://github.com/golang-migrate/migrate/issues/330
+	if version >= 0 || (version == database.NilVersion && dirty) {
+		query = `INSERT INTO ` + d.table() + ` (version, dirty) VALUES ($1, $2)`
+		if _, err := tx.ExecContext(d.ctx, query, version, dirty); err != nil {
+			_ = tx.Rollback()
+
+			return &database.Error{OrigErr: err, Query: []byte(query)}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return &database.Error{OrigErr: err, Err: "transaction commit failed"}
+	}
+
+	return nil
+}
+
+func (d *migrateDriver) Version() (int, bool, error) {
+	var (
+		version int
+		dirty   bool
+	)
+
+	query := `SELECT version, dirty FROM ` + d.table() + ` LIMIT 1`
+	err := d.conn.QueryRowContext(d.ctx, query).Scan(&version, &dirty)
+
+	var pgErr *pgconn.PgError
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return database.NilVersion, false, nil
+	case errors.As(err, &pgErr) && pgErr.Code == sqlStateUndefinedTable:
+		return database.NilVersion, false, nil // no migrations have run yet
+	case err != nil:
+		return 0, false, &database.Error{OrigErr: err, Query: []byte(query)}
+	default:
+		return version, dirty, nil
+	}
+}
+
+// Drop deletes all tables in the current schema.
+func (d *migrateDriver) Drop() error {
+	var tables []string
+
+	query := `SELECT table_name FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_type = 'BASE TABLE'`
+
+	rows, err := d.conn.QueryContext(d.ctx, query)
+	if err != nil {
+		return &database.Error{OrigErr: err, Query: []byte(query)}
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return &database.Error{OrigErr: err, Query: []byte(query)}
+		}
+
+		tables = append(tables, table)
+	}
+
+	if err := rows.Err(); err != nil {
+		return &database.Error{OrigErr: err, Query: []byte(query)}
+	}
+
+	for _, table := range tables {
+		query = `DROP TABLE IF EXISTS ` + quoteIdentifier(table) + ` CASCADE`
+		if _, err := d.conn.ExecContext(d.ctx, query); err != nil {
+			return &database.Error{OrigErr: err, Query: []byte(query)}
+		}
+	}
+
+	return nil
+}
+
+// quoteIdentifier quotes a Postgres identifier such as a table name.
+func quoteIdentifier(name string) string {
+	if end := strings.IndexRune(name, 0); end > -1 {
+		name = name[:end]
+	}
+
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
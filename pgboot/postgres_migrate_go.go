@@ -0,0 +1,213 @@
+package pgboot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/jmoiron/sqlx"
+)
+
+// goMigrationPrefix marks the body of a Go migration passed from
+// goMigrationsSource to migrateDriver. SQL files never contain a NUL byte.
+const goMigrationPrefix = "\x00goboot-go-migration:"
+
+var errDuplicateMigrationVersion = errors.New("duplicate Postgres migration version")
+
+// Migration is a Postgres migration written in Go, e.g. a data backfill that
+// needs application logic. Go migrations are ordered by version together
+// with the SQL migration files and recorded in the same migration history.
+type Migration struct {
+	// Version orders the migration between the SQL migration files and must
+	// not be used by any of them.
+	Version uint
+
+	// ID describes the migration, e.g. "rehash_passwords".
+	ID string
+
+	// Migrate applies the migration. The transaction is committed when
+	// Migrate returns without error and rolled back otherwise.
+	Migrate func(ctx context.Context, tx *sqlx.Tx) error
+
+	// Rollback reverts the migration when migrating down, leave nil when the
+	// migration cannot be reverted.
+	Rollback func(ctx context.Context, tx *sqlx.Tx) error
+}
+
+// goMigrationsSource implements a golang-migrate source.Driver that
+// interleaves Go migrations with the migrations of a SQL source.
+type goMigrationsSource struct {
+	sql        source.Driver // nil when there are only Go migrations
+	migrations map[uint]*Migration
+	versions   []uint
+}
+
+func newGoMigrationsSource(sql source.Driver, migrations []*Migration) (*goMigrationsSource, error) {
+	src := &goMigrationsSource{sql: sql, migrations: make(map[uint]*Migration, len(migrations))}
+
+	if sql != nil {
+		versions, err := sourceVersions(sql)
+		if err != nil {
+			return nil, err
+		}
+
+		src.versions = versions
+	}
+
+	for _, migration := range migrations {
+		if migration.Migrate == nil {
+			return nil, fmt.Errorf("missing Migrate function of Go migration %d %q", migration.Version, migration.ID)
+		}
+
+		if src.hasVersion(migration.Version) {
+			return nil, fmt.Errorf("%w %d of Go migration %q", errDuplicateMigrationVersion, migration.Version, migration.ID)
+		}
+
+		src.migrations[migration.Version] = migration
+		src.versions = append(src.versions, migration.Version)
+	}
+
+	sort.Slice(src.versions, func(i, j int) bool { return src.versions[i] < src.versions[j] })
+
+	return src, nil
+}
+
+func (s *goMigrationsSource) hasVersion(version uint) bool {
+	for _, v := range s.versions {
+		if v == version {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Open is not supported, goMigrationsSource is always created from a
+// configured source.
+func (s *goMigrationsSource) Open(string) (source.Driver, error) {
+	return nil, errDriverOpenNotSupported
+}
+
+func (s *goMigrationsSource) Close() error {
+	if s.sql == nil {
+		return nil
+	}
+
+	if err := s.sql.Close(); err != nil {
+		return fmt.Errorf("closing migrations source: %w", err)
+	}
+
+	return nil
+}
+
+func (s *goMigrationsSource) First() (uint, error) {
+	if len(s.versions) == 0 {
+		return 0, errNotExist("first")
+	}
+
+	return s.versions[0], nil
+}
+
+func (s *goMigrationsSource) Prev(version uint) (uint, error) {
+	i := sort.Search(len(s.versions), func(i int) bool { return s.versions[i] >= version })
+	if i == 0 || i == len(s.versions) || s.versions[i] != version {
+		return 0, errNotExist(fmt.Sprintf("prev for version %d", version))
+	}
+
+	return s.versions[i-1], nil
+}
+
+func (s *goMigrationsSource) Next(version uint) (uint, error) {
+	i := sort.Search(len(s.versions), func(i int) bool { return s.versions[i] > version })
+	if i == len(s.versions) {
+		return 0, errNotExist(fmt.Sprintf("next for version %d", version))
+	}
+
+	return s.versions[i], nil
+}
+
+func (s *goMigrationsSource) ReadUp(version uint) (io.ReadCloser, string, error) {
+	if migration, ok := s.migrations[version]; ok {
+		return goMigrationBody("up", version), migration.ID, nil
+	}
+
+	return s.readSQL(version, s.sql.ReadUp)
+}
+
+func (s *goMigrationsSource) ReadDown(version uint) (io.ReadCloser, string, error) {
+	if migration, ok := s.migrations[version]; ok {
+		if migration.Rollback == nil {
+			return nil, "", errNotExist(fmt.Sprintf("read down for version %d", version))
+		}
+
+		return goMigrationBody("down", version), migration.ID, nil
+	}
+
+	return s.readSQL(version, s.sql.ReadDown)
+}
+
+func (s *goMigrationsSource) readSQL(
+	version uint,
+	read func(uint) (io.ReadCloser, string, error),
+) (io.ReadCloser, string, error) {
+	if s.sql == nil {
+		return nil, "", errNotExist(fmt.Sprintf("read version %d", version))
+	}
+
+	return read(version)
+}
+
+// errNotExist is returned when a migration does not exist, golang-migrate
+// checks for os.ErrNotExist like it does for its own sources.
+func errNotExist(op string) error {
+	return &os.PathError{Op: op, Path: "migrations", Err: os.ErrNotExist}
+}
+
+func goMigrationBody(direction string, version uint) io.ReadCloser {
+	body := goMigrationPrefix + direction + ":" + strconv.FormatUint(uint64(version), 10)
+
+	return io.NopCloser(strings.NewReader(body))
+}
+
+// runGoMigration runs the Go migration referenced by body in a transaction.
+func (d *migrateDriver) runGoMigration(body string) error {
+	direction, v, _ := strings.Cut(strings.TrimPrefix(body, goMigrationPrefix), ":")
+
+	version, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return fmt.Errorf("parsing Go migration version %q: %w", v, err)
+	}
+
+	migration, ok := d.migrations[uint(version)]
+	if !ok {
+		return fmt.Errorf("missing Go migration %d", version)
+	}
+
+	f := migration.Migrate
+	if direction == "down" {
+		f = migration.Rollback
+	}
+
+	tx, err := d.conn.BeginTxx(d.ctx, nil)
+	if err != nil {
+		return fmt.Errorf("starting transaction for Go migration %d: %w", version, err)
+	}
+
+	if err := f(d.ctx, tx); err != nil {
+		_ = tx.Rollback()
+
+		return fmt.Errorf("running Go migration %d %q: %w", version, migration.ID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing Go migration %d: %w", version, err)
+	}
+
+	return nil
+}
@@ -1,8 +1,15 @@
 package pgboot_test
 
 import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
 	"testing"
+	"time"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/nielskrijger/goboot"
 	"github.com/nielskrijger/goboot/pgboot"
 	"github.com/nielskrijger/goboot/test"
@@ -10,6 +17,9 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+//go:embed testdata/migrations/*.sql
+var embeddedMigrations embed.FS
+
 type Record struct {
 	ID   int
 	Name string
@@ -41,3 +51,246 @@ func TestPostgresMigrate_SkipMigrationsWhenDirEmpty(t *testing.T) {
 
 	assert.Equal(t, "skipping db migrations; no migrations directory set", log.LastLine()["message"])
 }
+
+func TestPostgresMigrate_EmbeddedFS(t *testing.T) {
+	migrations, err := fs.Sub(embeddedMigrations, "testdata/migrations")
+	assert.Nil(t, err)
+
+	s := &pgboot.Postgres{MigrationsFS: migrations}
+	env := goboot.NewAppEnv("./testdata", "valid")
+	assert.Nil(t, s.Configure(env))
+	_, _ = s.DB.Exec("DROP TABLE IF EXISTS test_table")
+	_, _ = s.DB.Exec("DROP TABLE IF EXISTS schema_migrations")
+	assert.Nil(t, s.Init())
+
+	var records []Record
+	assert.Nil(t, s.DB.Select(&records, "SELECT * FROM test_table"))
+	assert.Len(t, records, 2)
+}
+
+func TestPostgresMigrate_VersionAndStatus(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "valid"), "./testdata/migrations")
+
+	version, dirty, err := s.Version(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, uint(2), version)
+	assert.False(t, dirty)
+
+	assert.Nil(t, s.MigrateDown(context.Background(), 1))
+
+	status, err := s.Status(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, []pgboot.MigrationStatus{
+		{Version: 1, Identifier: "create_table", Applied: true},
+		{Version: 2, Identifier: "insert_data", Applied: false},
+	}, status)
+
+	assert.Nil(t, s.MigrateTo(context.Background(), 2))
+
+	var count int
+	assert.Nil(t, s.DB.Get(&count, "SELECT count(*) FROM test_table"))
+	assert.Equal(t, 2, count)
+}
+
+func TestPostgresMigrate_ErrorDirty(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "valid"), "")
+	s.MigrationsDir = "./testdata/migrations-dirty"
+
+	assert.NotNil(t, s.Init()) // leaves the database dirty at version 2
+
+	version, dirty, err := s.Version(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, uint(2), version)
+	assert.True(t, dirty)
+
+	// Booting again reports the dirty state with instructions
+	err = s.Init()
+
+	var dirtyErr *pgboot.DirtyError
+	assert.ErrorAs(t, err, &dirtyErr)
+	assert.Equal(t, uint(2), dirtyErr.Version)
+	assert.Equal(t, 1, dirtyErr.PreviousVersion)
+	assert.Contains(t, err.Error(), "Postgres.Force(1)")
+
+	// Mark as reverted so the migration is retried
+	assert.Nil(t, s.Force(context.Background(), 1))
+
+	version, dirty, err = s.Version(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, uint(1), version)
+	assert.False(t, dirty)
+}
+
+func TestPostgresMigrate_ErrorMigrateDownInvalidSteps(t *testing.T) {
+	s := &pgboot.Postgres{}
+
+	for _, steps := range []int{0, -2} {
+		err := s.MigrateDown(context.Background(), steps)
+
+		assert.EqualError(
+			t,
+			err,
+			fmt.Sprintf("rolling back %d Postgres migrations: number of migrations to roll back must be positive", steps),
+		)
+	}
+}
+
+func TestPostgresMigrate_ErrorNoMigrationsConfigured(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "valid"), "")
+
+	_, _, err := s.Version(context.Background())
+
+	assert.EqualError(t, err, "no Postgres migrations configured; set MigrationsDir, MigrationsFS or Migrations")
+}
+
+func TestPostgresMigrate_ReleasesConnection(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "valid"), "./testdata/migrations")
+
+	assert.Nil(t, s.MigrateDown(context.Background(), 2))
+	assert.Nil(t, s.Migrate(context.Background(), "./testdata/migrations"))
+
+	assert.Equal(t, 0, s.DB.Stats().InUse)
+	assert.Nil(t, s.DB.Ping()) // pool is still open
+}
+
+func TestPostgresMigrate_ErrorContextCancelled(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "valid"), "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := s.Migrate(ctx, "./testdata/migrations")
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 0, s.DB.Stats().InUse)
+}
+
+func TestPostgresMigrate_GoMigrations(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "valid"), "")
+	s.MigrationsDir = "./testdata/migrations-go"
+	s.Migrations = []*pgboot.Migration{
+		{
+			Version: 2,
+			ID:      "insert_records",
+			Migrate: func(ctx context.Context, tx *sqlx.Tx) error {
+				_, err := tx.ExecContext(ctx, "INSERT INTO test_table (name) VALUES ('first'), ('second')")
+
+				return err //nolint:wrapcheck
+			},
+		},
+	}
+
+	assert.Nil(t, s.Init())
+
+	var names []string
+	assert.Nil(t, s.DB.Select(&names, "SELECT name FROM test_table ORDER BY id"))
+	assert.Equal(t, []string{"FIRST", "SECOND"}, names) // Go migration ran before migration 3
+
+	status, err := s.Status(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, []pgboot.MigrationStatus{
+		{Version: 1, Identifier: "create_table", Applied: true},
+		{Version: 2, Identifier: "insert_records", Applied: true},
+		{Version: 3, Identifier: "uppercase_names", Applied: true},
+	}, status)
+}
+
+func TestPostgresMigrate_GoMigrationsOnMigrationConnection(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "single-conn"), "")
+	s.Migrations = []*pgboot.Migration{
+		{
+			Version: 2,
+			ID:      "insert_records",
+			Migrate: func(ctx context.Context, tx *sqlx.Tx) error {
+				_, err := tx.ExecContext(ctx, "INSERT INTO test_table (name) VALUES ('first')")
+
+				return err //nolint:wrapcheck
+			},
+		},
+	}
+
+	// would deadlock when the Go migration waited for a second connection
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	assert.Nil(t, s.Migrate(ctx, "./testdata/migrations-go"))
+	assert.Equal(t, 1, countRecords(t, s))
+}
+
+func TestPostgresMigrate_GoMigrationRollsBackOnError(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "valid"), "")
+	s.MigrationsDir = "./testdata/migrations-go"
+	s.Migrations = []*pgboot.Migration{
+		{
+			Version: 2,
+			ID:      "insert_records",
+			Migrate: func(ctx context.Context, tx *sqlx.Tx) error {
+				if _, err := tx.ExecContext(ctx, "INSERT INTO test_table (name) VALUES ('first')"); err != nil {
+					return err //nolint:wrapcheck
+				}
+
+				return errors.New("backfill failed")
+			},
+		},
+	}
+
+	err := s.Init()
+
+	assert.ErrorContains(t, err, `running Go migration 2 "insert_records": backfill failed`)
+
+	var count int
+	assert.Nil(t, s.DB.Get(&count, "SELECT count(*) FROM test_table"))
+	assert.Equal(t, 0, count)
+
+	version, dirty, err := s.Version(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, uint(2), version)
+	assert.True(t, dirty)
+}
+
+func TestPostgresMigrate_ErrorDuplicateGoMigrationVersion(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "valid"), "")
+	s.MigrationsDir = "./testdata/migrations-go"
+	s.Migrations = []*pgboot.Migration{
+		{Version: 3, ID: "duplicate", Migrate: func(context.Context, *sqlx.Tx) error { return nil }},
+	}
+
+	err := s.Init()
+
+	assert.ErrorContains(t, err, `duplicate Postgres migration version 3 of Go migration "duplicate"`)
+}
+
+func TestPostgresMigrate_VerifyMode(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "verify"), "")
+	s.MigrationsDir = "./testdata/migrations"
+
+	err := s.Init()
+
+	assert.ErrorIs(t, err, goboot.ErrPendingMigrations)
+	assert.EqualError(t, err, "checking Postgres migrations: pending migrations: 1_create_table, 2_insert_data")
+}
+
+func TestPostgresMigrate_MigrationsModeOverridesConfig(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "valid"), "")
+	s.MigrationsDir = "./testdata/migrations"
+	s.MigrationsMode = goboot.MigrationsVerify
+
+	err := s.Init()
+
+	assert.ErrorIs(t, err, goboot.ErrPendingMigrations)
+}
+
+func TestPostgresMigrate_DryRunMode(t *testing.T) {
+	log := &test.Logger{}
+	env := goboot.NewAppEnv("./testdata", "dry-run")
+	env.Log = zerolog.New(log)
+	s := pgboot.NewTestPostgres(t, env, "")
+	s.MigrationsDir = "./testdata/migrations"
+
+	assert.Nil(t, s.Init())
+
+	version, _, err := s.Version(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, uint(0), version)
+	assert.Equal(t, "dry run; 2 pending Postgres migrations were not applied", log.LastLine()["message"])
+}
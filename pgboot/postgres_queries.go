@@ -0,0 +1,187 @@
+package pgboot
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v4/stdlib"
+)
+
+var (
+	errUnknownQuery   = errors.New("unknown Postgres query")
+	errDuplicateQuery = errors.New("duplicate Postgres query name")
+	errUnnamedQuery   = errors.New("SQL before the first \"-- name:\" comment")
+	errEmptyQuery     = errors.New("empty Postgres query")
+)
+
+// queryNamePattern matches the comment starting a named query, e.g. "-- name: GetUser".
+var queryNamePattern = regexp.MustCompile(`^--\s*name:\s*(\S+)\s*$`)
+
+// LoadQueries reads the named queries of the .sql files in the root of fsys
+// and validates them by preparing each query against the database. Typos and
+// columns that no longer exist therefore fail on boot rather than on the
+// first request.
+//
+// Each query starts with a "-- name: <Name>" comment and runs until the next
+// one or the end of the file:
+//
+//	-- name: GetUser
+//	SELECT id, email FROM users WHERE id = $1;
+//
+// A query must be a single statement using $1, $2, ... placeholders. Use
+// Query to get a loaded query by name.
+func (s *Postgres) LoadQueries(ctx context.Context, fsys fs.FS) error {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return fmt.Errorf("listing query files: %w", err)
+	}
+
+	queries := make(map[string]string)
+
+	for _, file := range files {
+		if err := readQueries(fsys, file, queries); err != nil {
+			return err
+		}
+	}
+
+	names := make([]string, 0, len(queries))
+	for name := range queries {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := s.prepare(ctx, queries[name]); err != nil {
+			return fmt.Errorf("validating Postgres query %q: %w", name, err)
+		}
+	}
+
+	s.queries = queries
+
+	s.log.Info().Int("queries", len(queries)).Msg("loaded Postgres queries")
+
+	return nil
+}
+
+// Query returns the query loaded by LoadQueries with the specified name.
+func (s *Postgres) Query(name string) (string, error) {
+	query, ok := s.queries[name]
+	if !ok {
+		return "", fmt.Errorf("%w %q", errUnknownQuery, name)
+	}
+
+	return query, nil
+}
+
+// readQueries adds the named queries in file to queries.
+func readQueries(fsys fs.FS, file string, queries map[string]string) error {
+	f, err := fsys.Open(file)
+	if err != nil {
+		return fmt.Errorf("opening query file: %w", err)
+	}
+
+	defer f.Close()
+
+	var (
+		name string
+		body strings.Builder
+	)
+
+	add := func() error {
+		query := strings.TrimSpace(body.String())
+
+		switch {
+		case name == "" && !onlyComments(query):
+			return fmt.Errorf("%w in %s", errUnnamedQuery, file)
+		case name == "":
+			return nil // e.g. a comment describing the file
+		case query == "":
+			return fmt.Errorf("%w %q in %s", errEmptyQuery, name, file)
+		}
+
+		if _, exists := queries[name]; exists {
+			return fmt.Errorf("%w %q in %s", errDuplicateQuery, name, file)
+		}
+
+		queries[name] = query
+
+		return nil
+	}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if match := queryNamePattern.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			if err := add(); err != nil {
+				return err
+			}
+
+			name = match[1]
+			body.Reset()
+
+			continue
+		}
+
+		body.WriteString(line)
+		body.WriteString("\n")
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading query file %s: %w", file, err)
+	}
+
+	return add()
+}
+
+func onlyComments(sql string) bool {
+	for _, line := range strings.Split(sql, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// prepare parses query and resolves its tables and columns without running it.
+func (s *Postgres) prepare(ctx context.Context, query string) error {
+	conn, err := s.DB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("reserving Postgres connection: %w", err)
+	}
+
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error { //nolint:wrapcheck // wrapped by the caller
+		pgxConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errNotPgxConn
+		}
+
+		_, err := pgxConn.Conn().PgConn().Prepare(ctx, "", query, nil)
+
+		return err //nolint:wrapcheck // wrapped by the caller
+	})
+}
+
+// queriesFS returns the file system with the named queries, nil when there are none.
+func (s *Postgres) queriesFS() fs.FS {
+	if s.QueriesFS != nil {
+		return s.QueriesFS
+	}
+
+	if s.QueriesDir != "" {
+		return os.DirFS(s.QueriesDir)
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package pgboot_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/nielskrijger/goboot"
+	"github.com/nielskrijger/goboot/pgboot"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostgresQueries_LoadOnInit(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "valid"), "./testdata/migrations")
+	s.QueriesDir = "./testdata/queries"
+
+	assert.Nil(t, s.Init())
+
+	query, err := s.Query("GetRecord")
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT id, name\nFROM test_table\nWHERE id = $1;", query)
+
+	var name string
+	assert.Nil(t, s.DB.Get(&name, query, 2))
+	assert.Equal(t, "Second record", name)
+
+	query, err = s.Query("InsertRecord")
+	assert.Nil(t, err)
+	assert.Equal(t, "INSERT INTO test_table (name) VALUES ($1) RETURNING id;", query)
+}
+
+func TestPostgresQueries_ErrorUnknownQuery(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "valid"), "./testdata/migrations")
+	s.QueriesDir = "./testdata/queries"
+
+	assert.Nil(t, s.Init())
+
+	_, err := s.Query("GetUser")
+	assert.EqualError(t, err, `unknown Postgres query "GetUser"`)
+}
+
+func TestPostgresQueries_ErrorInvalidQuery(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "valid"), "./testdata/migrations")
+	s.QueriesDir = "./testdata/queries-invalid"
+
+	assert.EqualError(
+		t,
+		s.Init(),
+		`loading Postgres queries: validating Postgres query "GetRecord": `+
+			`ERROR: column "title" does not exist (SQLSTATE 42703)`,
+	)
+}
+
+func TestPostgresQueries_ErrorDuplicateName(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "valid"), "./testdata/migrations")
+	fsys := fstest.MapFS{
+		"a.sql": {Data: []byte("-- name: ListRecords\nSELECT 1;")},
+		"b.sql": {Data: []byte("-- name: ListRecords\nSELECT 2;")},
+	}
+
+	err := s.LoadQueries(context.Background(), fsys)
+
+	assert.EqualError(t, err, `duplicate Postgres query name "ListRecords" in b.sql`)
+}
+
+func TestPostgresQueries_ErrorUnnamedQuery(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "valid"), "./testdata/migrations")
+	fsys := fstest.MapFS{"a.sql": {Data: []byte("SELECT 1;\n-- name: ListRecords\nSELECT 2;")}}
+
+	err := s.LoadQueries(context.Background(), fsys)
+
+	assert.EqualError(t, err, `SQL before the first "-- name:" comment in a.sql`)
+}
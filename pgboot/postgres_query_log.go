@@ -0,0 +1,60 @@
+package pgboot
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/rs/zerolog"
+)
+
+// queryLogger implements pgx.Logger and logs queries slower than the slow
+// query threshold as warnings. All other queries are logged at debug level
+// when debug logging is enabled. Query arguments are never logged, only
+// their number.
+type queryLogger struct {
+	log                zerolog.Logger
+	slowQueryThreshold time.Duration // 0 disables slow query logging
+	debug              bool
+}
+
+// newQueryLogger returns nil if neither slow query nor debug logging is enabled.
+func newQueryLogger(log zerolog.Logger, slowQueryThreshold time.Duration) *queryLogger {
+	debug := log.Debug().Enabled()
+	if slowQueryThreshold <= 0 && !debug {
+		return nil
+	}
+
+	return &queryLogger{log: log, slowQueryThreshold: slowQueryThreshold, debug: debug}
+}
+
+func (l *queryLogger) Log(_ context.Context, _ pgx.LogLevel, msg string, data map[string]any) {
+	if msg != "Query" && msg != "Exec" {
+		return // connection lifecycle messages
+	}
+
+	sql, _ := data["sql"].(string)
+	args, _ := data["args"].([]any)
+	duration, _ := data["time"].(time.Duration)
+	err, _ := data["err"].(error)
+
+	var event *zerolog.Event
+
+	switch {
+	case err == nil && l.slowQueryThreshold > 0 && duration >= l.slowQueryThreshold:
+		event = l.log.Warn()
+		msg = "slow Postgres query"
+	case l.debug:
+		event = l.log.Debug()
+		msg = "Postgres query"
+	default:
+		return
+	}
+
+	event.
+		Dur("duration", duration).
+		Str("sql", sql).
+		Int("args", len(args)).
+		Err(err).
+		Msg(msg)
+}
@@ -0,0 +1,148 @@
+package pgboot
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// replica is a connection pool to a read replica.
+type replica struct {
+	db      *sqlx.DB
+	url     string // redacted DSN for logging
+	healthy atomic.Bool
+}
+
+// Reader returns a connection pool for read-only queries. Queries are
+// balanced across the healthy replicas, the primary DB is returned when no
+// replicas are configured or none of them is healthy.
+//
+// Replicas lag behind the primary, use DB for reads that must see the
+// service's own writes.
+func (s *Postgres) Reader() *sqlx.DB {
+	n := len(s.replicas)
+	if n == 0 {
+		return s.DB
+	}
+
+	start := s.nextReplica.Add(1)
+
+	for i := 0; i < n; i++ {
+		r := s.replicas[(start+uint64(i))%uint64(n)]
+		if r.healthy.Load() {
+			return r.db
+		}
+	}
+
+	return s.DB
+}
+
+// connectReplicas connects to all configured replicas and starts checking
+// their health in the background. Unavailable replicas do not fail the boot,
+// queries fall back to the primary until they become healthy.
+func (s *Postgres) connectReplicas() error {
+	for _, dsn := range s.config.Replicas {
+		logURL, err := redactDSN(dsn)
+		if err != nil {
+			_ = s.closeReplicas()
+
+			return err
+		}
+
+		db, err := s.open(dsn)
+		if err != nil {
+			_ = s.closeReplicas()
+
+			return err
+		}
+
+		r := &replica{db: db, url: logURL.String()}
+		s.replicas = append(s.replicas, r)
+
+		s.log.Info().Msgf("connecting to replica %s", r.url)
+
+		if err := s.pingReplica(r); err != nil {
+			s.log.Warn().Err(err).Str("url", r.url).Msg("failed to connect to Postgres replica, reading from primary")
+		} else {
+			r.healthy.Store(true)
+			s.log.Info().Str("url", r.url).Msg("successfully connected to Postgres replica")
+		}
+	}
+
+	if len(s.replicas) > 0 {
+		s.startReplicaChecks()
+	}
+
+	return nil
+}
+
+func (s *Postgres) startReplicaChecks() {
+	stop := make(chan struct{})
+	s.stopReplicas = stop
+	replicas := s.replicas
+	ticker := time.NewTicker(s.config.ReplicaCheckInterval)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				for _, r := range replicas {
+					s.checkReplica(r)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// checkReplica pings the replica and logs when its health changes.
+func (s *Postgres) checkReplica(r *replica) {
+	err := s.pingReplica(r)
+	healthy := err == nil
+
+	if r.healthy.Swap(healthy) == healthy {
+		return
+	}
+
+	if healthy {
+		s.log.Info().Str("url", r.url).Msg("successfully connected to Postgres replica")
+	} else {
+		s.log.Warn().Err(err).Str("url", r.url).Msg("Postgres replica is unavailable, reading from primary")
+	}
+}
+
+func (s *Postgres) pingReplica(r *replica) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.ReplicaCheckInterval)
+	defer cancel()
+
+	if err := r.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("pinging replica: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Postgres) closeReplicas() error {
+	if s.stopReplicas != nil {
+		close(s.stopReplicas)
+		s.stopReplicas = nil
+	}
+
+	var closeErr error
+
+	for _, r := range s.replicas {
+		if err := r.db.Close(); err != nil && closeErr == nil {
+			closeErr = fmt.Errorf("closing replica %s: %w", r.url, err)
+		}
+	}
+
+	s.replicas = nil
+
+	return closeErr
+}
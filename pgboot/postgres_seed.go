@@ -0,0 +1,123 @@
+package pgboot
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const seedsTable = "schema_seeds"
+
+// Seed applies the SQL files in the seeds directory of this environment in
+// order of file name. Each file runs in a transaction and is recorded in the
+// schema_seeds table so it is applied only once.
+//
+// Seeding is skipped when SeedsDir or "postgres.seeds" is not set. Init only
+// seeds in the goboot.MigrationsApply mode.
+func (s *Postgres) Seed(ctx context.Context) error {
+	if s.SeedsDir == "" {
+		return nil
+	}
+
+	if s.config.Seeds == "" {
+		s.log.Info().Msg("skipping Postgres seeds; \"postgres.seeds\" not set for this environment")
+
+		return nil
+	}
+
+	dir, err := filepath.Abs(filepath.Join(s.SeedsDir, s.config.Seeds))
+	if err != nil {
+		return fmt.Errorf("reading seeds path: %w", err)
+	}
+
+	files, err := seedFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	query := `CREATE TABLE IF NOT EXISTS ` + seedsTable + ` (
+		name TEXT PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`
+	if _, err := s.DB.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("creating %s table: %w", seedsTable, err)
+	}
+
+	s.log.Info().Msgf("running Postgres seeds from %s", dir)
+
+	for _, name := range files {
+		if err := s.applySeed(ctx, dir, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applySeed runs seed file name unless it has been applied before.
+func (s *Postgres) applySeed(ctx context.Context, dir string, name string) error {
+	return s.WithTx(ctx, nil, func(tx *sqlx.Tx) error {
+		// seeds may load a lot of data, which shouldn't be limited by the timeouts of the application
+		if err := SetLocalTimeouts(ctx, tx, noTimeouts); err != nil {
+			return err
+		}
+
+		// lock the seeds table so concurrently booting instances apply each seed once
+		if _, err := tx.ExecContext(ctx, `LOCK TABLE `+seedsTable+` IN EXCLUSIVE MODE`); err != nil {
+			return fmt.Errorf("locking %s table: %w", seedsTable, err)
+		}
+
+		var applied bool
+
+		query := `SELECT EXISTS (SELECT 1 FROM ` + seedsTable + ` WHERE name = $1)`
+		if err := tx.GetContext(ctx, &applied, query, name); err != nil {
+			return fmt.Errorf("reading seed %s: %w", name, err)
+		}
+
+		if applied {
+			return nil
+		}
+
+		seed, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return fmt.Errorf("reading seed %s: %w", name, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, string(seed)); err != nil {
+			return fmt.Errorf("running seed %s: %w", name, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `INSERT INTO `+seedsTable+` (name) VALUES ($1)`, name); err != nil {
+			return fmt.Errorf("recording seed %s: %w", name, err)
+		}
+
+		s.log.Info().Msgf("applied Postgres seed %s", name)
+
+		return nil
+	})
+}
+
+// seedFiles returns the names of the SQL files in dir sorted by name.
+func seedFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading seeds directory %s: %w", dir, err)
+	}
+
+	var files []string
+
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".sql") {
+			files = append(files, entry.Name())
+		}
+	}
+
+	sort.Strings(files)
+
+	return files, nil
+}
@@ -0,0 +1,46 @@
+package pgboot_test
+
+import (
+	"testing"
+
+	"github.com/nielskrijger/goboot"
+	"github.com/nielskrijger/goboot/pgboot"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostgresSeed_AppliesSeedsOnce(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "seeds"), "./testdata/migrations")
+	s.SeedsDir = "./testdata/seeds"
+
+	assert.Nil(t, s.Init())
+	assert.Nil(t, s.Init()) // the seed would violate the unique name when applied twice
+
+	var names []string
+	assert.Nil(t, s.DB.Select(&names, "SELECT name FROM test_table WHERE name = 'Seed record'"))
+	assert.Equal(t, []string{"Seed record"}, names)
+}
+
+func TestPostgresSeed_SkipWhenNotApplyingMigrations(t *testing.T) {
+	for _, mode := range []goboot.MigrationsMode{goboot.MigrationsVerify, goboot.MigrationsDryRun} {
+		s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "seeds"), "")
+		s.SeedsDir = "./testdata/seeds"
+		s.MigrationsMode = mode
+
+		assert.Nil(t, s.Init(), mode)
+
+		var exists bool
+		assert.Nil(t, s.DB.Get(&exists, "SELECT to_regclass('schema_seeds') IS NOT NULL"))
+		assert.False(t, exists, mode)
+	}
+}
+
+func TestPostgresSeed_SkipWhenNotConfigured(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "valid"), "./testdata/migrations")
+	s.SeedsDir = "./testdata/seeds"
+
+	assert.Nil(t, s.Init())
+
+	var count int
+	assert.Nil(t, s.DB.Get(&count, "SELECT count(*) FROM test_table WHERE name = 'Seed record'"))
+	assert.Equal(t, 0, count)
+}
@@ -0,0 +1,75 @@
+package pgboot
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// startStatsReporter reports the statistics of the connection pool of db
+// every StatsInterval until stopped. Any running reporter is stopped first.
+func (s *Postgres) startStatsReporter(db *sqlx.DB) {
+	s.stopStatsReporter()
+
+	if s.config.StatsInterval < 0 {
+		return
+	}
+
+	stop := make(chan struct{})
+	s.stopStats = stop
+	ticker := time.NewTicker(s.config.StatsInterval)
+
+	go func() {
+		defer ticker.Stop()
+
+		var previous sql.DBStats
+
+		for {
+			select {
+			case <-ticker.C:
+				stats := db.Stats()
+				s.reportStats(stats, previous)
+				previous = stats
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+func (s *Postgres) stopStatsReporter() {
+	if s.stopStats != nil {
+		close(s.stopStats)
+		s.stopStats = nil
+	}
+}
+
+// reportStats logs the connection pool statistics and passes them to the
+// StatsHook. Logs a warning when queries had to wait for a connection since
+// the previous report.
+func (s *Postgres) reportStats(stats sql.DBStats, previous sql.DBStats) {
+	s.log.Debug().
+		Int("maxOpenConnections", stats.MaxOpenConnections).
+		Int("openConnections", stats.OpenConnections).
+		Int("inUse", stats.InUse).
+		Int("idle", stats.Idle).
+		Int64("waitCount", stats.WaitCount).
+		Dur("waitDuration", stats.WaitDuration).
+		Int64("maxIdleClosed", stats.MaxIdleClosed).
+		Int64("maxIdleTimeClosed", stats.MaxIdleTimeClosed).
+		Int64("maxLifetimeClosed", stats.MaxLifetimeClosed).
+		Msg("Postgres connection pool statistics")
+
+	if waits := stats.WaitCount - previous.WaitCount; waits > 0 {
+		s.log.Warn().
+			Int("maxOpenConnections", stats.MaxOpenConnections).
+			Int64("waitCount", waits).
+			Dur("waitDuration", stats.WaitDuration-previous.WaitDuration).
+			Msgf("%d Postgres queries waited for a connection; consider increasing postgres.maxOpenConns", waits)
+	}
+
+	if s.StatsHook != nil {
+		s.StatsHook(stats)
+	}
+}
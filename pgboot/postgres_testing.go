@@ -0,0 +1,276 @@
+package pgboot
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/nielskrijger/goboot"
+	"github.com/nielskrijger/goboot/test"
+)
+
+// NewTestPostgres returns a configured and initialized Postgres service that
+// uses its own uniquely named schema. This allows tests to run in parallel
+// against a single Postgres database without seeing each other's rows.
+//
+// The schema is created using the "postgres" settings of env and set as the
+// search_path of every connection in the pool. Migrations in migrationsDir
+// are run inside the schema, leave migrationsDir empty to skip migrations.
+//
+// The schema and everything in it is dropped when the test finishes.
+func NewTestPostgres(t testing.TB, env *goboot.AppEnv, migrationsDir string) *Postgres {
+	t.Helper()
+
+	s := &Postgres{MigrationsDir: migrationsDir}
+	if err := s.Configure(env); err != nil {
+		t.Fatalf("configuring Postgres: %s", err)
+	}
+
+	baseDSN := s.config.DSN
+	schema := test.UniqueName(t)
+
+	if _, err := s.DB.Exec(fmt.Sprintf("CREATE SCHEMA %q", schema)); err != nil {
+		t.Fatalf("creating test schema %q: %s", schema, err)
+	}
+
+	t.Cleanup(func() {
+		_ = s.Close()
+
+		if err := dropTestSchema(baseDSN, schema); err != nil {
+			t.Errorf("dropping test schema %q: %s", schema, err)
+		}
+	})
+
+	if err := s.DB.Close(); err != nil {
+		t.Fatalf("closing Postgres connection: %s", err)
+	}
+
+	dsn, err := withSearchPath(baseDSN, schema)
+	if err != nil {
+		t.Fatalf("setting search_path: %s", err)
+	}
+
+	s.config.DSN = dsn
+	if err := s.connect(); err != nil {
+		t.Fatalf("connecting to test schema %q: %s", schema, err)
+	}
+
+	if err := s.Init(); err != nil {
+		t.Fatalf("initializing Postgres in test schema %q: %s", schema, err)
+	}
+
+	return s
+}
+
+// withSearchPath adds the search_path runtime parameter to a URL-formatted DSN.
+func withSearchPath(dsn string, schema string) (string, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", fmt.Errorf("invalid Postgres dsn: %w", err)
+	}
+
+	q := u.Query()
+	q.Set("search_path", schema)
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+func dropTestSchema(dsn string, schema string) error {
+	db, err := sqlx.Open("pgx", dsn)
+	if err != nil {
+		return fmt.Errorf("connection to postgres: %w", err)
+	}
+
+	defer db.Close()
+
+	if _, err := db.Exec(fmt.Sprintf("DROP SCHEMA IF EXISTS %q CASCADE", schema)); err != nil {
+		return fmt.Errorf("dropping schema: %w", err)
+	}
+
+	return nil
+}
+
+// RoundTripError is returned by RoundTripMigrations when a down migration
+// does not revert the schema changes of its up migration.
+type RoundTripError struct {
+	Version uint
+
+	// Missing contains schema objects that existed before the up migration
+	// but not after the down migration.
+	Missing []string
+
+	// Remaining contains schema objects created by the up migration that
+	// still exist after the down migration.
+	Remaining []string
+}
+
+func (e *RoundTripError) Error() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "down migration %d does not revert the schema", e.Version)
+
+	for _, obj := range e.Missing {
+		fmt.Fprintf(&b, "\n- %s", obj)
+	}
+
+	for _, obj := range e.Remaining {
+		fmt.Fprintf(&b, "\n+ %s", obj)
+	}
+
+	return b.String()
+}
+
+// VerifyMigrationsRoundTrip applies each migration in migrationsDir up, down
+// and up again in a scratch schema, see NewTestPostgres. The test fails with
+// the offending version if a down migration does not cleanly revert the
+// schema or an up migration cannot be applied again.
+func VerifyMigrationsRoundTrip(t testing.TB, env *goboot.AppEnv, migrationsDir string) {
+	t.Helper()
+
+	s := NewTestPostgres(t, env, "")
+	s.MigrationsDir = migrationsDir
+
+	if err := s.RoundTripMigrations(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// RoundTripMigrations applies each pending migration up, down and up again
+// and compares the schema before the up migration with the schema after the
+// down migration. Only use it against a scratch database, the down
+// migrations may drop data.
+func (s *Postgres) RoundTripMigrations(ctx context.Context) error {
+	status, err := s.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range status {
+		if migration.Applied {
+			continue
+		}
+
+		before, err := s.dumpSchema(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err := s.MigrateTo(ctx, migration.Version); err != nil {
+			return fmt.Errorf("applying migration %d: %w", migration.Version, err)
+		}
+
+		if err := s.MigrateDown(ctx, 1); err != nil {
+			return fmt.Errorf("reverting migration %d: %w", migration.Version, err)
+		}
+
+		after, err := s.dumpSchema(ctx)
+		if err != nil {
+			return err
+		}
+
+		if missing, remaining := diffSchema(before, after); len(missing) > 0 || len(remaining) > 0 {
+			return &RoundTripError{Version: migration.Version, Missing: missing, Remaining: remaining}
+		}
+
+		if err := s.MigrateTo(ctx, migration.Version); err != nil {
+			return fmt.Errorf("applying migration %d again after reverting it: %w", migration.Version, err)
+		}
+	}
+
+	return nil
+}
+
+// dumpSchema describes the tables, columns, constraints, indexes, sequences,
+// views, functions, triggers and enum, composite and domain types in the
+// current schema, one object per line.
+func (s *Postgres) dumpSchema(ctx context.Context) ([]string, error) {
+	var objects []string
+
+	query := `
+		SELECT 'column ' || table_name || '.' || column_name || ' ' || data_type ||
+			CASE WHEN is_nullable = 'NO' THEN ' NOT NULL' ELSE '' END ||
+			COALESCE(' DEFAULT ' || column_default, '')
+		FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name <> $1
+		UNION ALL
+		SELECT 'constraint ' || rel.relname || '.' || con.conname || ' ' || pg_get_constraintdef(con.oid)
+		FROM pg_constraint con
+		JOIN pg_class rel ON rel.oid = con.conrelid
+		WHERE rel.relnamespace = (SELECT oid FROM pg_namespace WHERE nspname = current_schema()) AND rel.relname <> $1
+		UNION ALL
+		SELECT 'index ' || indexdef
+		FROM pg_indexes
+		WHERE schemaname = current_schema() AND tablename <> $1
+		UNION ALL
+		SELECT 'sequence ' || sequence_name || ' ' || data_type
+		FROM information_schema.sequences
+		WHERE sequence_schema = current_schema()
+		UNION ALL
+		SELECT 'view ' || viewname || ' ' || definition
+		FROM pg_views
+		WHERE schemaname = current_schema()
+		UNION ALL
+		SELECT 'function ' || p.proname || '(' || pg_get_function_arguments(p.oid) || ') ' || md5(p.prosrc)
+		FROM pg_proc p
+		WHERE p.pronamespace = (SELECT oid FROM pg_namespace WHERE nspname = current_schema())
+		UNION ALL
+		SELECT 'trigger ' || pg_get_triggerdef(tg.oid)
+		FROM pg_trigger tg
+		JOIN pg_class rel ON rel.oid = tg.tgrelid
+		WHERE rel.relnamespace = (SELECT oid FROM pg_namespace WHERE nspname = current_schema())
+			AND NOT tg.tgisinternal AND rel.relname <> $1
+		UNION ALL
+		SELECT 'type ' || t.typname || ' ' || CASE t.typtype
+			WHEN 'e' THEN 'enum (' || (
+				SELECT COALESCE(string_agg(quote_literal(e.enumlabel), ', ' ORDER BY e.enumsortorder), '')
+				FROM pg_enum e WHERE e.enumtypid = t.oid
+			) || ')'
+			WHEN 'd' THEN 'domain ' || format_type(t.typbasetype, t.typtypmod)
+			ELSE 'composite (' || (
+				SELECT COALESCE(
+					string_agg(a.attname || ' ' || format_type(a.atttypid, a.atttypmod), ', ' ORDER BY a.attnum), ''
+				)
+				FROM pg_attribute a WHERE a.attrelid = t.typrelid AND a.attnum > 0 AND NOT a.attisdropped
+			) || ')'
+		END
+		FROM pg_type t
+		LEFT JOIN pg_class rel ON rel.oid = t.typrelid
+		WHERE t.typnamespace = (SELECT oid FROM pg_namespace WHERE nspname = current_schema())
+			AND (t.typtype IN ('e', 'd') OR (t.typtype = 'c' AND rel.relkind = 'c'))
+		ORDER BY 1`
+	if err := s.DB.SelectContext(ctx, &objects, query, migrationsTable); err != nil {
+		return nil, fmt.Errorf("dumping Postgres schema: %w", err)
+	}
+
+	return objects, nil
+}
+
+// diffSchema returns the objects only in before and the objects only in after.
+func diffSchema(before []string, after []string) (missing []string, remaining []string) {
+	inAfter := make(map[string]bool, len(after))
+	for _, obj := range after {
+		inAfter[obj] = true
+	}
+
+	inBefore := make(map[string]bool, len(before))
+
+	for _, obj := range before {
+		inBefore[obj] = true
+
+		if !inAfter[obj] {
+			missing = append(missing, obj)
+		}
+	}
+
+	for _, obj := range after {
+		if !inBefore[obj] {
+			remaining = append(remaining, obj)
+		}
+	}
+
+	return missing, remaining
+}
@@ -0,0 +1,65 @@
+package pgboot_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nielskrijger/goboot"
+	"github.com/nielskrijger/goboot/pgboot"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTestPostgres_IsolatedSchemas(t *testing.T) {
+	for _, name := range []string{"first", "second"} {
+		name := name
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "valid"), "./testdata/migrations")
+
+			var schema string
+			assert.Nil(t, s.DB.Get(&schema, "SELECT current_schema()"))
+			assert.True(t, strings.HasPrefix(schema, "test_testnewtestpostgres_isolatedschemas_"))
+
+			_, err := s.DB.Exec("INSERT INTO test_table (name) VALUES ($1)", name)
+			assert.Nil(t, err)
+
+			var records []Record
+			assert.Nil(t, s.DB.Select(&records, "SELECT * FROM test_table ORDER BY id"))
+			assert.Len(t, records, 3)
+			assert.Equal(t, name, records[2].Name)
+		})
+	}
+}
+
+func TestVerifyMigrationsRoundTrip(t *testing.T) {
+	pgboot.VerifyMigrationsRoundTrip(t, goboot.NewAppEnv("./testdata", "valid"), "./testdata/migrations")
+}
+
+func TestRoundTripMigrations_ErrorDownMigrationIncomplete(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "valid"), "")
+	s.MigrationsDir = "./testdata/migrations-broken-down"
+
+	err := s.RoundTripMigrations(context.Background())
+
+	var roundTripErr *pgboot.RoundTripError
+	assert.ErrorAs(t, err, &roundTripErr)
+	assert.Equal(t, uint(2), roundTripErr.Version)
+	assert.Empty(t, roundTripErr.Missing)
+	assert.Equal(t, []string{"column test_table.email character varying"}, roundTripErr.Remaining)
+}
+
+func TestRoundTripMigrations_ErrorDownMigrationKeepsType(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "valid"), "")
+	s.MigrationsDir = "./testdata/migrations-leaky-enum"
+
+	err := s.RoundTripMigrations(context.Background())
+
+	var roundTripErr *pgboot.RoundTripError
+	assert.ErrorAs(t, err, &roundTripErr)
+	assert.Equal(t, uint(2), roundTripErr.Version)
+	assert.Empty(t, roundTripErr.Missing)
+	assert.Equal(t, []string{"type record_status enum ('active', 'archived')"}, roundTripErr.Remaining)
+}
@@ -0,0 +1,77 @@
+package pgboot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Timeouts contains the statement, lock and idle in transaction timeouts of
+// a session or transaction. Zero leaves a timeout unchanged and -1 disables
+// it. Timeouts are rounded up to whole milliseconds.
+type Timeouts struct {
+	Statement         time.Duration
+	Lock              time.Duration
+	IdleInTransaction time.Duration
+}
+
+// noTimeouts disables the statement and lock timeouts of migrations and
+// seeds, which may run long DDL or wait for locks held by the application.
+var noTimeouts = Timeouts{Statement: -1, Lock: -1}
+
+func (c *PostgresConfig) timeouts() Timeouts {
+	return Timeouts{
+		Statement:         c.StatementTimeout,
+		Lock:              c.LockTimeout,
+		IdleInTransaction: c.IdleInTransactionTimeout,
+	}
+}
+
+// runtimeParams returns the timeouts that are set as Postgres runtime
+// parameters in milliseconds. Timeouts are rounded up so a sub-millisecond
+// timeout doesn't become 0, which disables it.
+func (t Timeouts) runtimeParams() map[string]string {
+	params := make(map[string]string, 3)
+
+	for name, timeout := range map[string]time.Duration{
+		StatementTimeout:         t.Statement,
+		LockTimeout:              t.Lock,
+		IdleInTransactionTimeout: t.IdleInTransaction,
+	} {
+		switch {
+		case timeout < 0:
+			params[name] = "0"
+		case timeout > 0:
+			ms := (timeout + time.Millisecond - 1) / time.Millisecond
+			params[name] = strconv.FormatInt(int64(ms), 10)
+		}
+	}
+
+	return params
+}
+
+// SetLocalTimeouts overrides the timeouts configured for the service for the
+// remainder of tx, e.g. to allow a slow report query or to fail fast when a
+// row is locked:
+//
+//	err := s.WithTx(ctx, nil, func(tx *sqlx.Tx) error {
+//		if err := pgboot.SetLocalTimeouts(ctx, tx, pgboot.Timeouts{Lock: 100 * time.Millisecond}); err != nil {
+//			return err
+//		}
+//		...
+//	})
+//
+// Queries exceeding a timeout fail with a TimeoutError.
+func SetLocalTimeouts(ctx context.Context, tx *sqlx.Tx, timeouts Timeouts) error {
+	for name, value := range timeouts.runtimeParams() {
+		// SET LOCAL does not support query parameters, set_config with is_local is the same
+		if _, err := tx.ExecContext(ctx, `SELECT set_config($1, $2, true)`, name, value); err != nil {
+			return fmt.Errorf("setting local %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
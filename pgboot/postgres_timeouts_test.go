@@ -0,0 +1,98 @@
+package pgboot_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/nielskrijger/goboot"
+	"github.com/nielskrijger/goboot/pgboot"
+	"github.com/stretchr/testify/assert"
+)
+
+func showSetting(t *testing.T, q sqlx.Queryer, name string) string {
+	t.Helper()
+
+	var value string
+	assert.Nil(t, sqlx.Get(q, &value, "SHOW "+name))
+
+	return value
+}
+
+func TestPostgresTimeouts_RuntimeParams(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "timeouts"), "")
+
+	assert.Equal(t, "100ms", showSetting(t, s.DB, "statement_timeout"))
+	assert.Equal(t, "50ms", showSetting(t, s.DB, "lock_timeout"))
+	assert.Equal(t, "0", showSetting(t, s.DB, "idle_in_transaction_session_timeout"))
+}
+
+func TestPostgresTimeouts_StatementTimeoutError(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "timeouts"), "")
+
+	err := s.WithTx(context.Background(), nil, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec("SELECT pg_sleep(1)")
+
+		return err //nolint:wrapcheck
+	})
+
+	var timeoutErr *pgboot.TimeoutError
+	if assert.ErrorAs(t, err, &timeoutErr) {
+		assert.Equal(t, pgboot.StatementTimeout, timeoutErr.Timeout)
+	}
+
+	assert.ErrorIs(t, err, pgboot.ErrTimeout)
+	assert.NotErrorIs(t, err, pgboot.ErrQueryCanceled)
+}
+
+func TestPostgresTimeouts_SetLocalTimeouts(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "timeouts"), "")
+
+	err := s.WithTx(context.Background(), nil, func(tx *sqlx.Tx) error {
+		timeouts := pgboot.Timeouts{Statement: 2 * time.Second, Lock: -1}
+		if err := pgboot.SetLocalTimeouts(context.Background(), tx, timeouts); err != nil {
+			return err
+		}
+
+		assert.Equal(t, "2s", showSetting(t, tx, "statement_timeout"))
+		assert.Equal(t, "0", showSetting(t, tx, "lock_timeout"))
+
+		_, err := tx.Exec("SELECT pg_sleep(0.2)")
+
+		return err //nolint:wrapcheck
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "100ms", showSetting(t, s.DB, "statement_timeout"))
+}
+
+func TestPostgresTimeouts_RoundUpToMilliseconds(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "timeouts"), "")
+
+	err := s.WithTx(context.Background(), nil, func(tx *sqlx.Tx) error {
+		timeouts := pgboot.Timeouts{Statement: 1500 * time.Microsecond, Lock: 500 * time.Microsecond}
+		if err := pgboot.SetLocalTimeouts(context.Background(), tx, timeouts); err != nil {
+			return err
+		}
+
+		assert.Equal(t, "2ms", showSetting(t, tx, "statement_timeout"))
+		assert.Equal(t, "1ms", showSetting(t, tx, "lock_timeout"))
+
+		return nil
+	})
+
+	assert.Nil(t, err)
+}
+
+func TestPostgresTimeouts_NotAppliedToMigrationsAndSeeds(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "tiny-timeouts"), "")
+	s.MigrationsDir = "./testdata/migrations-slow"
+	s.SeedsDir = "./testdata/seeds"
+
+	assert.Nil(t, s.Init())
+
+	// the timeouts are restored on the single connection used for migrations
+	assert.Equal(t, "10ms", showSetting(t, s.DB, "statement_timeout"))
+	assert.Equal(t, "10ms", showSetting(t, s.DB, "lock_timeout"))
+}
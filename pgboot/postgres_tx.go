@@ -0,0 +1,81 @@
+package pgboot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+)
+
+// WithTx runs f in a transaction. The transaction is committed when f returns
+// without error and rolled back when f returns an error or panics.
+//
+// When the transaction fails with a serialization failure or deadlock the
+// whole transaction is retried up to postgres.txMaxRetries times, so f must
+// be safe to run more than once.
+//
+// Errors are classified by TranslateError, e.g. a unique violation can be
+// checked with errors.Is(err, ErrUniqueViolation).
+func (s *Postgres) WithTx(ctx context.Context, opts *sql.TxOptions, f func(tx *sqlx.Tx) error) error {
+	backoff := s.config.TxRetryBackoff
+
+	for retries := 0; ; retries++ {
+		err := s.runTx(ctx, opts, f)
+		if err == nil || !isRetryableTxError(err) || retries >= s.config.TxMaxRetries {
+			return err
+		}
+
+		s.log.Warn().
+			Err(err).
+			Int("retry", retries+1).
+			Msgf("Postgres transaction failed, retrying in %s", backoff)
+
+		s.clock.Sleep(backoff)
+
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("retrying Postgres transaction: %w", err)
+		}
+
+		backoff *= 2
+	}
+}
+
+func (s *Postgres) runTx(ctx context.Context, opts *sql.TxOptions, f func(tx *sqlx.Tx) error) error {
+	tx, err := s.DB.BeginTxx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("starting Postgres transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+
+			panic(p)
+		}
+	}()
+
+	if err := f(tx); err != nil {
+		_ = tx.Rollback()
+
+		return TranslateError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return TranslateError(fmt.Errorf("committing Postgres transaction: %w", err))
+	}
+
+	return nil
+}
+
+// isRetryableTxError returns true if err is caused by a serialization failure
+// or deadlock and the transaction may succeed when retried.
+func isRetryableTxError(err error) bool {
+	return errors.Is(TranslateError(err), ErrSerializationFailure)
+}
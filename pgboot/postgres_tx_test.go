@@ -0,0 +1,140 @@
+package pgboot_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jmoiron/sqlx"
+	"github.com/nielskrijger/goboot"
+	"github.com/nielskrijger/goboot/pgboot"
+	"github.com/nielskrijger/goboot/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostgresWithTx_Commit(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "valid"), "./testdata/migrations")
+
+	err := s.WithTx(context.Background(), nil, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec("INSERT INTO test_table (name) VALUES ('Third record')")
+
+		return err //nolint:wrapcheck
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, countRecords(t, s))
+}
+
+func TestPostgresWithTx_RollbackOnError(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "valid"), "./testdata/migrations")
+	errTest := errors.New("test")
+
+	err := s.WithTx(context.Background(), nil, func(tx *sqlx.Tx) error {
+		_, _ = tx.Exec("INSERT INTO test_table (name) VALUES ('Third record')")
+
+		return errTest
+	})
+
+	assert.ErrorIs(t, err, errTest)
+	assert.Equal(t, 2, countRecords(t, s))
+}
+
+func TestPostgresWithTx_RollbackOnPanic(t *testing.T) {
+	s := pgboot.NewTestPostgres(t, goboot.NewAppEnv("./testdata", "valid"), "./testdata/migrations")
+
+	assert.PanicsWithValue(t, "test", func() {
+		_ = s.WithTx(context.Background(), nil, func(tx *sqlx.Tx) error {
+			_, _ = tx.Exec("INSERT INTO test_table (name) VALUES ('Third record')")
+
+			panic("test")
+		})
+	})
+
+	assert.Equal(t, 2, countRecords(t, s))
+	assert.Equal(t, 0, s.DB.Stats().InUse)
+}
+
+func TestPostgresWithTx_RetrySerializationFailure(t *testing.T) {
+	clock := test.NewFakeClock(time.Now())
+	env := goboot.NewAppEnv("./testdata", "valid")
+	env.Clock = clock
+	s := pgboot.NewTestPostgres(t, env, "./testdata/migrations")
+	attempts := 0
+
+	err := s.WithTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelSerializable}, func(tx *sqlx.Tx) error {
+		attempts++
+		if attempts < 3 {
+			return &pgconn.PgError{Code: "40001", Message: "could not serialize access"}
+		}
+
+		_, err := tx.Exec("INSERT INTO test_table (name) VALUES ('Third record')")
+
+		return err //nolint:wrapcheck
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, []time.Duration{50 * time.Millisecond, 100 * time.Millisecond}, clock.Sleeps())
+	assert.Equal(t, 3, countRecords(t, s))
+}
+
+func TestPostgresWithTx_ErrorMaxRetries(t *testing.T) {
+	env := goboot.NewAppEnv("./testdata", "valid")
+	env.Clock = test.NewFakeClock(time.Now())
+	s := pgboot.NewTestPostgres(t, env, "./testdata/migrations")
+	attempts := 0
+
+	err := s.WithTx(context.Background(), nil, func(tx *sqlx.Tx) error {
+		attempts++
+
+		return &pgconn.PgError{Code: "40P01", Message: "deadlock detected"}
+	})
+
+	var pgErr *pgconn.PgError
+	assert.ErrorAs(t, err, &pgErr)
+	assert.Equal(t, 4, attempts)
+}
+
+func countRecords(t *testing.T, s *pgboot.Postgres) int {
+	t.Helper()
+
+	var count int
+	assert.Nil(t, s.DB.Get(&count, "SELECT count(*) FROM test_table"))
+
+	return count
+}
+
+// newTestPostgresWithClock returns a test service using clock that has run the
+// migrations in migrationsDir followed by the Go migrations.
+func newTestPostgresWithClock(
+	t *testing.T,
+	clock goboot.Clock,
+	migrationsDir string,
+	migrations ...*pgboot.Migration,
+) *pgboot.Postgres {
+	t.Helper()
+
+	env := goboot.NewAppEnv("./testdata", "valid")
+	env.Clock = clock
+
+	s := pgboot.NewTestPostgres(t, env, migrationsDir)
+	s.Migrations = migrations
+	assert.Nil(t, s.Init())
+
+	return s
+}
+
+// assertRow asserts query selects a single row equal to expected, a struct
+// with db tags.
+func assertRow(t *testing.T, s *pgboot.Postgres, expected any, query string) {
+	t.Helper()
+
+	row := reflect.New(reflect.TypeOf(expected))
+	if assert.Nil(t, s.DB.Get(row.Interface(), query)) {
+		assert.Equal(t, expected, row.Elem().Interface())
+	}
+}
@@ -0,0 +1,2 @@
+-- forgot to drop the email column
+SELECT 1;
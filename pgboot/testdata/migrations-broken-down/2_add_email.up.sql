@@ -0,0 +1 @@
+ALTER TABLE test_table ADD COLUMN email varchar(100);
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS test_table (
+    id SERIAL,
+    name varchar(100) NOT NULL UNIQUE,
+    PRIMARY KEY (id)
+);
@@ -0,0 +1,3 @@
+INSERT INTO test_table (name) VALUES ('Half applied');
+
+SELECT * FROM table_does_not_exist;
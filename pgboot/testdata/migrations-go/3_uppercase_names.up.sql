@@ -0,0 +1 @@
+UPDATE test_table SET name = upper(name);
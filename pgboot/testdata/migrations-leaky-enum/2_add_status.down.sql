@@ -0,0 +1,2 @@
+-- forgot to drop the record_status type
+ALTER TABLE test_table DROP COLUMN status;
@@ -0,0 +1,2 @@
+CREATE TYPE record_status AS ENUM ('active', 'archived');
+ALTER TABLE test_table ADD COLUMN status record_status;
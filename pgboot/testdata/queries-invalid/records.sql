@@ -0,0 +1,2 @@
+-- name: GetRecord
+SELECT id, title FROM test_table WHERE id = $1;
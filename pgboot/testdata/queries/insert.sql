@@ -0,0 +1,4 @@
+-- Queries changing records.
+
+-- name: InsertRecord
+INSERT INTO test_table (name) VALUES ($1) RETURNING id;
@@ -0,0 +1,7 @@
+-- name: GetRecord
+SELECT id, name
+FROM test_table
+WHERE id = $1;
+
+-- name: ListRecords
+SELECT id, name FROM test_table ORDER BY id;
@@ -0,0 +1,2 @@
+INSERT INTO test_table (name)
+VALUES ('Seed record');
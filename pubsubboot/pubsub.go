@@ -9,10 +9,15 @@ import (
 	"unicode/utf8"
 
 	"cloud.google.com/go/pubsub"
+	"cloud.google.com/go/pubsub/pstest"
 	"github.com/nielskrijger/goboot"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
 )
 
@@ -25,6 +30,7 @@ const (
 	RetryDelay            = time.Minute * 2
 	AckDeadline           = 10 * time.Second
 	MaxAttributeLength    = 1024
+	ConnectTimeout        = 10 * time.Second
 )
 
 // PubSub adds some utility methods to the Google cloud
@@ -41,9 +47,16 @@ type PubSub struct {
 	// DeadLetter is the channel used for dead letter messages.
 	DeadLetterChannel *Channel
 
+	// InProcessServer is the fake Pub/Sub server the client is connected to
+	// when the WithInProcessServer option is used, nil otherwise.
+	InProcessServer *pstest.Server
+
 	projectID string
 	log       zerolog.Logger
+	clock     goboot.Clock
 	options   []Option
+	inProcess bool
+	retry     *goboot.RetryPolicy
 }
 
 // RichMessage embeds the raw gcloud pubsub message with additional details
@@ -75,6 +88,22 @@ type Channel struct {
 	MaxRetryAge time.Duration
 }
 
+// Publisher publishes events to a PubSub channel.
+type Publisher interface {
+	PublishEvent(ctx context.Context, channel string, eventName string, payload any) error
+	TryPublishEvent(ctx context.Context, channel string, eventName string, payload any)
+}
+
+// Receiver receives messages from a PubSub channel.
+type Receiver interface {
+	Receive(ctx context.Context, channel string, f func(context.Context, *RichMessage)) error
+}
+
+var (
+	_ Publisher = (*PubSub)(nil)
+	_ Receiver  = (*PubSub)(nil)
+)
+
 type Option func(*PubSub)
 
 // WithChannel option adds a channel with a topic and a subscription.
@@ -120,6 +149,27 @@ func WithDeadLetter(ch *Channel) func(*PubSub) {
 	}
 }
 
+// WithInProcessServer option connects the Pub/Sub service to an in-process fake
+// server rather than Google Cloud or the Pub/Sub emulator. This allows tests to
+// run without any external dependencies.
+//
+// The fake server is started on Configure and stopped on Close. Topics and
+// subscriptions still need to be created using CreateAll (or Init). Use the
+// InProcessServer field to inspect published messages.
+func WithInProcessServer() func(*PubSub) {
+	return func(cl *PubSub) {
+		cl.inProcess = true
+	}
+}
+
+// WithRetryPolicy option sets how often the connectivity check on Configure is
+// retried. Without this option the "pubsub.retry" configuration is used.
+func WithRetryPolicy(policy goboot.RetryPolicy) func(*PubSub) {
+	return func(cl *PubSub) {
+		cl.retry = &policy
+	}
+}
+
 // NewPubSubService configures a new Service and connects to the pubsub server.
 func NewPubSubService(projectID string, options ...Option) *PubSub {
 	return &PubSub{
@@ -137,18 +187,97 @@ func (s *PubSub) Name() string {
 // the client connection to gcloud pubsub.
 func (s *PubSub) Configure(env *goboot.AppEnv) error {
 	s.log = env.Log
+	s.clock = env.ClockOrDefault()
+
 	for _, option := range s.options {
 		option(s)
 	}
 
+	if s.retry == nil {
+		policy, err := goboot.RetryPolicyFromConfig(env.Config, "pubsub.retry")
+		if err != nil {
+			return fmt.Errorf("parsing \"pubsub.retry\": %w", err)
+		}
+
+		s.retry = &policy
+	}
+
+	*s.retry = s.retry.WithDefaults()
+
+	if s.inProcess {
+		return s.connectInProcess()
+	}
+
 	client, err := pubsub.NewClient(context.Background(), s.projectID)
 	if err != nil {
 		return fmt.Errorf("connecting to gcloud pubsub: %w", err)
 	}
 
+	s.Client = client
+
+	if err := s.testConnectivity(context.Background()); err != nil {
+		return err
+	}
+
 	s.log.Info().Msgf("connected to %s pubsub", s.projectID)
+
+	return nil
+}
+
+// testConnectivity lists a topic of the project to check the client can reach
+// the Pub/Sub server, retrying according to the service's retry policy.
+func (s *PubSub) testConnectivity(ctx context.Context) error {
+	listTopics := func(ctx context.Context) error {
+		// the client retries unavailable servers itself until the context is done
+		ctx, cancel := context.WithTimeout(ctx, ConnectTimeout)
+		defer cancel()
+
+		_, err := s.Client.Topics(ctx).Next()
+		if err == nil || errors.Is(err, iterator.Done) {
+			return nil
+		}
+
+		// credentials without the topics.list permission still reach the server
+		if status.Code(err) == codes.PermissionDenied {
+			return nil
+		}
+
+		return err //nolint:wrapcheck // wrapped below
+	}
+
+	err := s.retry.Retry(ctx, s.clock, listTopics, func(err error, wait time.Duration) {
+		s.log.Warn().Err(err).Msgf("failed to connect to %s pubsub, retrying in %s", s.projectID, wait)
+	})
+	if err != nil {
+		return fmt.Errorf("connecting to gcloud pubsub: %w", err)
+	}
+
+	return nil
+}
+
+// connectInProcess starts an in-process fake Pub/Sub server and connects
+// the client to it.
+func (s *PubSub) connectInProcess() error {
+	s.InProcessServer = pstest.NewServer()
+
+	conn, err := grpc.Dial(s.InProcessServer.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return fmt.Errorf("connecting to in-process pubsub server: %w", err)
+	}
+
+	client, err := pubsub.NewClient(context.Background(), s.projectID, option.WithGRPCConn(conn))
+	if err != nil {
+		return fmt.Errorf("connecting to in-process pubsub server: %w", err)
+	}
+
 	s.Client = client
 
+	if err := s.testConnectivity(context.Background()); err != nil {
+		return err
+	}
+
+	s.log.Info().Msgf("connected to in-process %s pubsub", s.projectID)
+
 	return nil
 }
 
@@ -190,6 +319,14 @@ func (s *PubSub) Close() error {
 		return fmt.Errorf("closing %s service: %w", s.Name(), err)
 	}
 
+	if s.InProcessServer != nil {
+		if err := s.InProcessServer.Close(); err != nil {
+			return fmt.Errorf("closing in-process pubsub server: %w", err)
+		}
+
+		s.InProcessServer = nil
+	}
+
 	return nil
 }
 
@@ -259,7 +396,7 @@ func (msg *RichMessage) TryDeadLetter(ctx context.Context, cause error) {
 //
 // Returns an error if no deadlettering the message failed.
 func (msg *RichMessage) RetryableError(ctx context.Context, cause error) error {
-	if time.Since(msg.PublishTime) > msg.Channel.MaxRetryAge {
+	if msg.Service.clock.Since(msg.PublishTime) > msg.Channel.MaxRetryAge {
 		return msg.DeadLetter(ctx, cause)
 	}
 
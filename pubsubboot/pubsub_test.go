@@ -322,3 +322,87 @@ func TestPubSubStringTrimLeftBytes(t *testing.T) {
 		assert.Equal(t, tt.out, pubsubboot.TrimLeftBytes(tt.in, tt.maxBytes))
 	}
 }
+
+func newInProcessPubSubService(t *testing.T, clock goboot.Clock) *pubsubboot.PubSub {
+	t.Helper()
+
+	s := pubsubboot.NewPubSubService("metrix-io",
+		pubsubboot.WithInProcessServer(),
+		pubsubboot.WithChannel(&pubsubboot.Channel{ID: "test-channel", TopicID: topicID, SubscriptionID: subID}),
+		pubsubboot.WithDeadLetter(&pubsubboot.Channel{TopicID: deadLetterTopicID, SubscriptionID: deadLetterSubID}),
+	)
+	env := goboot.NewAppEnv("../testdata", "")
+	env.Log = zerolog.New(&test.Logger{})
+	env.Clock = clock
+
+	assert.Nil(t, s.Configure(env))
+	assert.Nil(t, s.Init())
+
+	t.Cleanup(func() { _ = s.Close() })
+
+	return s
+}
+
+func TestPubSubInProcess_PublishAndReceive(t *testing.T) {
+	s := newInProcessPubSubService(t, goboot.SystemClock{})
+	ctx := context.Background()
+
+	assert.Nil(t, s.PublishEvent(ctx, "test-channel", "ev1", "test message"))
+	assert.Len(t, s.InProcessServer.Messages(), 1)
+
+	msgs, err := s.ReceiveNr(ctx, "test-channel", 1)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "ev1", msgs[0].Attributes["event"])
+	assert.Equal(t, "\"test message\"", string(msgs[0].Data))
+}
+
+func TestPubSubInProcess_DeadLetter(t *testing.T) {
+	s := newInProcessPubSubService(t, goboot.SystemClock{})
+	ctx := context.Background()
+
+	_ = s.PublishEvent(ctx, "test-channel", "ev1", "test message")
+	msgs, _ := s.ReceiveNr(ctx, "test-channel", 1)
+	assert.Nil(t, msgs[0].DeadLetter(ctx, errTest))
+
+	dead, err := s.ReceiveNr(ctx, "dead-letter", 1)
+
+	assert.Nil(t, err)
+	assert.Equal(t, msgs[0].ID, dead[0].Attributes["originalMessageID"])
+	assert.Equal(t, "test error", dead[0].Attributes["error"])
+}
+
+func TestPubSubInProcess_RetryableErrorWithinMaxRetryAge(t *testing.T) {
+	clock := test.NewFakeClock(time.Now())
+	s := newInProcessPubSubService(t, clock)
+	ctx := context.Background()
+
+	_ = s.PublishEvent(ctx, "test-channel", "ev1", "test message")
+	msgs, _ := s.ReceiveNr(ctx, "test-channel", 1)
+	clock.Advance(pubsubboot.RetryDelay - time.Second)
+
+	assert.Nil(t, msgs[0].RetryableError(ctx, errTest))
+	assert.Len(t, s.InProcessServer.Messages(), 1) // nothing was dead-lettered
+}
+
+func TestPubSubInProcess_RetryableErrorMaxRetryAgeExpired(t *testing.T) {
+	clock := test.NewFakeClock(time.Now())
+	s := newInProcessPubSubService(t, clock)
+	ctx := context.Background()
+
+	_ = s.PublishEvent(ctx, "test-channel", "ev1", "test message")
+	msgs, _ := s.ReceiveNr(ctx, "test-channel", 1)
+	clock.Advance(pubsubboot.RetryDelay + time.Second)
+
+	assert.Nil(t, msgs[0].RetryableError(ctx, errTest))
+
+	dead, _ := s.ReceiveNr(ctx, "dead-letter", 1)
+	assert.Equal(t, msgs[0].ID, dead[0].Attributes["originalMessageID"])
+}
+
+func TestPubSubInProcess_Close(t *testing.T) {
+	s := newInProcessPubSubService(t, goboot.SystemClock{})
+
+	assert.Nil(t, s.Close())
+	assert.Nil(t, s.InProcessServer)
+}
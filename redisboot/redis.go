@@ -1,6 +1,7 @@
 package redisboot
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -10,11 +11,6 @@ import (
 	"github.com/rs/zerolog"
 )
 
-const (
-	defaultRedisConnectMaxRetries    = 5
-	defaultRedisConnectRetryDuration = 5 * time.Second
-)
-
 var (
 	errMissingConfig = errors.New("missing Redis configuration")
 	errMissingURL    = errors.New("config \"redis.url\" is required")
@@ -37,18 +33,39 @@ type RedisConfig struct {
 	// Dial timeout for establishing new connections. Default is 5 seconds.
 	DialTimeout time.Duration `yaml:"dialTimeout"`
 
-	// Number of retries upon initial connect. Default is 5 times. Set -1 to disable
+	// Retry policy of the initial connect.
+	Retry goboot.RetryPolicy `yaml:"retry"`
+
+	// Deprecated: use Retry.MaxRetries. Number of attempts upon initial connect, -1 disables retries.
 	ConnectMaxRetries int `yaml:"connectMaxRetries"`
 
-	// Time between retries for initial connect attempts. Default is 5 seconds.
+	// Deprecated: use Retry.InitialInterval. Fixed time between initial connect attempts.
 	ConnectRetryDuration time.Duration `yaml:"connectRetryDuration"`
 }
 
+// Accessor contains the Redis commands most applications use.
+type Accessor interface {
+	Get(key string) *redis.StringCmd
+	Set(key string, value any, expiration time.Duration) *redis.StatusCmd
+	SetNX(key string, value any, expiration time.Duration) *redis.BoolCmd
+	Del(keys ...string) *redis.IntCmd
+	Exists(keys ...string) *redis.IntCmd
+	Expire(key string, expiration time.Duration) *redis.BoolCmd
+	Incr(key string) *redis.IntCmd
+	TTL(key string) *redis.DurationCmd
+}
+
+var _ Accessor = (*Redis)(nil)
+
 // Redis implements the AppService interface.
+//
+// The redis client is embedded, all Redis commands can be called on the
+// service directly.
 type Redis struct {
-	Client *redis.Client
+	*redis.Client
 
-	log zerolog.Logger
+	log   zerolog.Logger
+	clock goboot.Clock
 }
 
 func (s *Redis) Name() string {
@@ -57,6 +74,7 @@ func (s *Redis) Name() string {
 
 func (s *Redis) Configure(env *goboot.AppEnv) error {
 	s.log = env.Log
+	s.clock = env.ClockOrDefault()
 	redisCfg := &RedisConfig{}
 
 	if !env.Config.InConfig("redis") {
@@ -88,42 +106,29 @@ func (s *Redis) Configure(env *goboot.AppEnv) error {
 
 	s.Client = redis.NewClient(opts)
 
-	if redisCfg.ConnectMaxRetries == 0 {
-		redisCfg.ConnectMaxRetries = defaultRedisConnectMaxRetries
-	}
-
-	if redisCfg.ConnectRetryDuration == 0*time.Second {
-		redisCfg.ConnectRetryDuration = defaultRedisConnectRetryDuration
-	}
+	redisCfg.Retry = redisCfg.Retry.
+		WithLegacyConnectSettings(redisCfg.ConnectMaxRetries, redisCfg.ConnectRetryDuration).
+		WithDefaults()
 
 	return s.testConnectivity(redisCfg)
 }
 
 func (s *Redis) testConnectivity(cfg *RedisConfig) error {
-	for retries := 1; ; retries++ {
-		if err := s.Client.Ping().Err(); err != nil {
-			if retries < cfg.ConnectMaxRetries {
-				s.log.Warn().
-					Err(err).
-					Str("url", cfg.URL).
-					Int("db", cfg.DB).
-					Msgf("failed to connect to redis, retrying in %s", cfg.ConnectRetryDuration)
-			} else {
-				return fmt.Errorf(
-					"failed to connect to redis after %d retries: %w",
-					cfg.ConnectMaxRetries,
-					err,
-				)
-			}
-
-			time.Sleep(cfg.ConnectRetryDuration)
-		} else {
-			s.log.Info().Msg("successfully connected to redis")
-
-			break
-		}
+	ping := func(context.Context) error { return s.Client.Ping().Err() }
+
+	err := cfg.Retry.Retry(context.Background(), s.clock, ping, func(err error, wait time.Duration) {
+		s.log.Warn().
+			Err(err).
+			Str("url", cfg.URL).
+			Int("db", cfg.DB).
+			Msgf("failed to connect to redis, retrying in %s", wait)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect to redis after %d attempts: %w", cfg.Retry.Attempts(), err)
 	}
 
+	s.log.Info().Msg("successfully connected to redis")
+
 	return nil
 }
 
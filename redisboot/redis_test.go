@@ -30,5 +30,5 @@ func TestRedis_ErrorEmptyURL(t *testing.T) {
 func TestRedis_ErrorOnConnect(t *testing.T) {
 	s := &redisboot.Redis{}
 	err := s.Configure(goboot.NewAppEnv("./testdata", "invalid"))
-	assert.EqualError(t, err, "failed to connect to redis after 5 retries: dial tcp 1.2.3.4:6379: i/o timeout")
+	assert.EqualError(t, err, "failed to connect to redis after 5 attempts: dial tcp 1.2.3.4:6379: i/o timeout")
 }
@@ -0,0 +1,178 @@
+package goboot
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	defaultRetryMaxRetries      = 5
+	defaultRetryInitialInterval = time.Second
+	defaultRetryMaxInterval     = 30 * time.Second
+	defaultRetryMultiplier      = 2
+	defaultRetryJitter          = 0.2
+
+	legacyConnectMaxAttempts = 5
+)
+
+// RetryPolicy determines how often and how long services wait before
+// retrying a failed operation such as their initial connectivity check.
+// Zero values are replaced by their defaults.
+type RetryPolicy struct {
+	// Number of retries after the first attempt. Default is 5. Set -1 to disable.
+	MaxRetries int `yaml:"maxRetries"`
+
+	// Time before the first retry. Default is 1 second.
+	InitialInterval time.Duration `yaml:"initialInterval"`
+
+	// Maximum time between retries. Default is 30 seconds.
+	MaxInterval time.Duration `yaml:"maxInterval"`
+
+	// Factor the interval is multiplied with after every retry. Default is 2, set 1 for a fixed interval.
+	Multiplier float64 `yaml:"multiplier"`
+
+	// Randomizes each interval by up to this fraction, e.g. 0.2 waits between 80% and 120% of the
+	// interval. Default is 0.2. Set -1 to disable.
+	Jitter float64 `yaml:"jitter"`
+}
+
+// RetryPolicyFromConfig reads a RetryPolicy from specified configuration key,
+// e.g. "elasticsearch.retry". Returns the default policy if the key is not set.
+func RetryPolicyFromConfig(cfg *viper.Viper, key string) (RetryPolicy, error) {
+	var policy RetryPolicy
+
+	if err := cfg.UnmarshalKey(key, &policy); err != nil {
+		return policy, fmt.Errorf("parsing %q configuration: %w", key, err)
+	}
+
+	return policy, nil
+}
+
+// WithDefaults returns the policy with all zero values replaced by their
+// defaults.
+func (p RetryPolicy) WithDefaults() RetryPolicy {
+	if p.MaxRetries == 0 {
+		p.MaxRetries = defaultRetryMaxRetries
+	}
+
+	if p.InitialInterval <= 0 {
+		p.InitialInterval = defaultRetryInitialInterval
+	}
+
+	if p.MaxInterval <= 0 {
+		p.MaxInterval = defaultRetryMaxInterval
+	}
+
+	if p.MaxInterval < p.InitialInterval {
+		p.MaxInterval = p.InitialInterval
+	}
+
+	if p.Multiplier <= 0 {
+		p.Multiplier = defaultRetryMultiplier
+	}
+
+	if p.Jitter == 0 {
+		p.Jitter = defaultRetryJitter
+	}
+
+	return p
+}
+
+// WithLegacyConnectSettings applies the deprecated "connectMaxRetries" and
+// "connectRetryDuration" settings of the pgboot and redisboot configuration.
+// These count attempts rather than retries, wait a fixed interval and default
+// to 5 attempts when only the interval is set. Values already set in p take
+// precedence.
+func (p RetryPolicy) WithLegacyConnectSettings(maxAttempts int, interval time.Duration) RetryPolicy {
+	if maxAttempts == 0 && interval > 0 {
+		maxAttempts = legacyConnectMaxAttempts
+	}
+
+	if maxAttempts != 0 && p.MaxRetries == 0 {
+		p.MaxRetries = maxAttempts - 1
+		if p.MaxRetries <= 0 {
+			p.MaxRetries = -1
+		}
+	}
+
+	if interval > 0 && p.InitialInterval == 0 {
+		p.InitialInterval = interval
+		p.MaxInterval = interval
+		p.Multiplier = 1
+		p.Jitter = -1
+	}
+
+	return p
+}
+
+// Attempts returns the maximum number of times Retry calls its function.
+func (p RetryPolicy) Attempts() int {
+	if p = p.WithDefaults(); p.MaxRetries < 0 {
+		return 1
+	}
+
+	return p.MaxRetries + 1
+}
+
+// Retry calls f until it succeeds, the retries are exhausted or ctx is done,
+// and returns the last error of f. Before waiting for the next attempt
+// onRetry is called with the error and the wait time, e.g. to log a warning.
+func (p RetryPolicy) Retry(
+	ctx context.Context,
+	clock Clock,
+	f func(ctx context.Context) error,
+	onRetry func(err error, wait time.Duration),
+) error {
+	p = p.WithDefaults()
+
+	for attempt := 1; ; attempt++ {
+		err := f(ctx)
+		if err == nil || attempt >= p.Attempts() || ctx.Err() != nil {
+			return err
+		}
+
+		wait := p.Backoff(attempt)
+
+		if onRetry != nil {
+			onRetry(err, wait)
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-clock.After(wait):
+		}
+	}
+}
+
+// Backoff returns the time to wait before the specified retry, starting at 1
+// for the first retry.
+func (p RetryPolicy) Backoff(retry int) time.Duration {
+	p = p.WithDefaults()
+	interval := p.InitialInterval
+
+	for i := 1; i < retry && interval < p.MaxInterval; i++ {
+		interval = time.Duration(float64(interval) * p.Multiplier)
+	}
+
+	if interval > p.MaxInterval {
+		interval = p.MaxInterval
+	}
+
+	return p.jitter(interval)
+}
+
+// jitter randomizes interval by up to the Jitter fraction.
+func (p RetryPolicy) jitter(interval time.Duration) time.Duration {
+	if p.Jitter <= 0 {
+		return interval
+	}
+
+	delta := p.Jitter * float64(interval)
+
+	return time.Duration(float64(interval) - delta + rand.Float64()*2*delta) //nolint:gosec // no security impact
+}
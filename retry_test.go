@@ -0,0 +1,109 @@
+package goboot_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nielskrijger/goboot"
+	"github.com/nielskrijger/goboot/test"
+	"github.com/stretchr/testify/assert"
+)
+
+var errRetryTest = errors.New("test")
+
+func TestRetryPolicy_ExponentialBackoff(t *testing.T) {
+	clock := test.NewAutoAdvancingFakeClock(time.Now())
+	policy := goboot.RetryPolicy{
+		MaxRetries:      4,
+		InitialInterval: time.Second,
+		MaxInterval:     5 * time.Second,
+		Multiplier:      3,
+		Jitter:          -1,
+	}
+	attempts := 0
+
+	err := policy.Retry(context.Background(), clock, func(ctx context.Context) error {
+		attempts++
+
+		return errRetryTest
+	}, nil)
+
+	assert.ErrorIs(t, err, errRetryTest)
+	assert.Equal(t, 5, attempts)
+	assert.Equal(t, []time.Duration{time.Second, 3 * time.Second, 5 * time.Second, 5 * time.Second}, clock.Sleeps())
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := goboot.RetryPolicy{InitialInterval: time.Second, MaxInterval: time.Minute, Jitter: -1}
+
+	assert.Equal(t, time.Second, policy.Backoff(1))
+	assert.Equal(t, 8*time.Second, policy.Backoff(4))
+	assert.Equal(t, time.Minute, policy.Backoff(100))
+}
+
+func TestRetryPolicy_StopOnSuccess(t *testing.T) {
+	clock := test.NewAutoAdvancingFakeClock(time.Now())
+	attempts := 0
+	var waits []time.Duration
+
+	err := goboot.RetryPolicy{}.Retry(context.Background(), clock, func(ctx context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return errRetryTest
+		}
+
+		return nil
+	}, func(err error, wait time.Duration) {
+		waits = append(waits, wait)
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, waits, clock.Sleeps())
+	assert.InDelta(t, time.Second, waits[0], float64(200*time.Millisecond)) // default jitter is 20%
+	assert.InDelta(t, 2*time.Second, waits[1], float64(400*time.Millisecond))
+}
+
+func TestRetryPolicy_StopWhenContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+
+	err := goboot.RetryPolicy{}.Retry(ctx, goboot.SystemClock{}, func(ctx context.Context) error {
+		attempts++
+		cancel()
+
+		return errRetryTest
+	}, nil)
+
+	assert.ErrorIs(t, err, errRetryTest)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestRetryPolicy_Disabled(t *testing.T) {
+	policy := goboot.RetryPolicy{MaxRetries: -1}
+	attempts := 0
+
+	err := policy.Retry(context.Background(), goboot.SystemClock{}, func(ctx context.Context) error {
+		attempts++
+
+		return errRetryTest
+	}, nil)
+
+	assert.ErrorIs(t, err, errRetryTest)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestRetryPolicy_LegacyConnectSettingsDefaultAttempts(t *testing.T) {
+	policy := goboot.RetryPolicy{}.WithLegacyConnectSettings(0, time.Millisecond)
+
+	assert.Equal(t, 5, policy.Attempts())
+	assert.Equal(t, goboot.RetryPolicy{
+		MaxRetries:      4,
+		InitialInterval: time.Millisecond,
+		MaxInterval:     time.Millisecond,
+		Multiplier:      1,
+		Jitter:          -1,
+	}, policy)
+}
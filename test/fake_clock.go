@@ -0,0 +1,110 @@
+package test
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock implements goboot.Clock with a time that only changes when told
+// to. Sleep returns immediately and advances the clock instead. The channel
+// returned by After receives once the clock has been advanced far enough, so
+// poll loops waiting on it block until the test calls Advance.
+type FakeClock struct {
+	mu          sync.Mutex
+	now         time.Time
+	sleeps      []time.Duration
+	waiters     []fakeWaiter
+	autoAdvance bool
+}
+
+type fakeWaiter struct {
+	until time.Time
+	ch    chan time.Time
+}
+
+// NewFakeClock returns a FakeClock set to specified time.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// NewAutoAdvancingFakeClock returns a FakeClock set to specified time that
+// advances the clock on After like Sleep does, so After returns immediately.
+// Use it for code waiting synchronously such as goboot.RetryPolicy.Retry,
+// not for background loops which would spin.
+func NewAutoAdvancingFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now, autoAdvance: true}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *FakeClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// Sleep advances the clock by d and records the duration.
+func (c *FakeClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sleeps = append(c.sleeps, d)
+	c.advance(d)
+}
+
+// After records the duration and returns a channel that receives the time
+// once the clock has been advanced by d. An auto-advancing clock advances by
+// d right away.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sleeps = append(c.sleeps, d)
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, fakeWaiter{until: c.now.Add(d), ch: ch})
+
+	if c.autoAdvance {
+		c.advance(d)
+	} else {
+		c.advance(0) // fires when d <= 0
+	}
+
+	return ch
+}
+
+// Advance moves the clock forward by d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.advance(d)
+}
+
+// advance moves the clock forward by d and fires the After channels that are
+// due. Must be called with mu held.
+func (c *FakeClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+
+	waiters := c.waiters[:0]
+
+	for _, w := range c.waiters {
+		if w.until.After(c.now) {
+			waiters = append(waiters, w)
+		} else {
+			w.ch <- c.now
+		}
+	}
+
+	c.waiters = waiters
+}
+
+// Sleeps returns the durations of all Sleep and After calls so far.
+func (c *FakeClock) Sleeps() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]time.Duration(nil), c.sleeps...)
+}
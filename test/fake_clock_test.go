@@ -0,0 +1,45 @@
+package test_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nielskrijger/goboot"
+	"github.com/nielskrijger/goboot/test"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ goboot.Clock = (*test.FakeClock)(nil)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := test.NewFakeClock(start)
+
+	clock.Sleep(time.Second)
+	clock.Advance(time.Minute)
+	clock.Sleep(2 * time.Second)
+
+	assert.Equal(t, start.Add(time.Minute+3*time.Second), clock.Now())
+	assert.Equal(t, time.Minute+3*time.Second, clock.Since(start))
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, clock.Sleeps())
+}
+
+func TestFakeClock_AfterBlocksUntilAdvanced(t *testing.T) {
+	clock := test.NewFakeClock(time.Now())
+	ch := clock.After(time.Second)
+
+	clock.Advance(time.Second - 1)
+	assert.Len(t, ch, 0)
+
+	clock.Advance(1)
+	assert.Len(t, ch, 1)
+	assert.Equal(t, []time.Duration{time.Second}, clock.Sleeps())
+}
+
+func TestFakeClock_AutoAdvancingAfter(t *testing.T) {
+	start := time.Now()
+	clock := test.NewAutoAdvancingFakeClock(start)
+
+	assert.Len(t, clock.After(time.Second), 1)
+	assert.Equal(t, start.Add(time.Second), clock.Now())
+}
@@ -0,0 +1,36 @@
+package test
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const (
+	uniqueNamePrefix        = "test_"
+	uniqueNameMaxNameLength = 40
+	uniqueNameRandomBytes   = 4
+)
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// UniqueName returns a unique name derived from the test name that is safe
+// to use as Postgres schema, Elasticsearch index or DynamoDB table name,
+// e.g. "test_mytest_subtest_1a2b3c4d".
+func UniqueName(t testing.TB) string {
+	t.Helper()
+
+	name := invalidNameChars.ReplaceAllString(strings.ToLower(t.Name()), "_")
+	if len(name) > uniqueNameMaxNameLength {
+		name = name[:uniqueNameMaxNameLength]
+	}
+
+	b := make([]byte, uniqueNameRandomBytes)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatalf("generating unique name: %s", err)
+	}
+
+	return uniqueNamePrefix + name + "_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,18 @@
+package test_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/nielskrijger/goboot/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUniqueName(t *testing.T) {
+	t.Run("Special Chars-1.x", func(t *testing.T) {
+		name := test.UniqueName(t)
+
+		assert.Regexp(t, regexp.MustCompile(`^test_testuniquename_special_chars_1_x_[0-9a-f]{8}$`), name)
+		assert.NotEqual(t, name, test.UniqueName(t))
+	})
+}